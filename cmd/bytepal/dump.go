@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/Pwalne/bytepal"
+	"github.com/Pwalne/bytepal/hexdump"
+	"github.com/Pwalne/bytepal/schema"
+)
+
+// errUsage is returned for invalid flags, which the flag set has already reported.
+var errUsage = errors.New("invalid arguments")
+
+func dump(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("dump", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	schemaFile := flags.String("schema", "", "schema `file` to decode the input with")
+	root := flags.String("struct", "", "struct of the schema to decode, the last one declared by default")
+	traceFile := flags.String("trace", "", "JSON spans `file` recorded from a trace to annotate the input with")
+	hexInput := flags.Bool("hex", false, "read the input as hex text, whitespace is ignored")
+	littleEndian := flags.Bool("le", false, "decode fields without an order as little endian")
+	color := flags.Bool("color", false, "highlight skipped and unconsumed bytes")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if *schemaFile != "" && *traceFile != "" {
+		return fmt.Errorf("-schema and -trace are mutually exclusive")
+	}
+	if *root != "" && *schemaFile == "" {
+		return fmt.Errorf("-struct needs a -schema")
+	}
+
+	data, err := readInput(flags.Arg(0), stdin, *hexInput)
+	if err != nil {
+		return err
+	}
+	var spans []hexdump.Span
+	var decodeErr error
+	switch {
+	case *schemaFile != "":
+		spans, decodeErr = decodeSpans(data, *schemaFile, *root, *littleEndian)
+		if spans == nil {
+			return decodeErr
+		}
+	case *traceFile != "":
+		file, err := os.Open(*traceFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if spans, err = hexdump.ReadSpans(file); err != nil {
+			return err
+		}
+	default:
+		_, err := io.WriteString(stdout, hex.Dump(data))
+		return err
+	}
+	if err := hexdump.Annotate(stdout, data, spans, hexdump.Options{Color: *color}); err != nil {
+		return err
+	}
+	return decodeErr
+}
+
+// readInput reads the named file, or stdin if name is empty.
+func readInput(name string, stdin io.Reader, hexInput bool) ([]byte, error) {
+	var data []byte
+	var err error
+	if name == "" {
+		data, err = ioutil.ReadAll(stdin)
+	} else {
+		data, err = ioutil.ReadFile(name)
+	}
+	if err != nil || !hexInput {
+		return data, err
+	}
+	text := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, string(data))
+	if data, err = hex.DecodeString(text); err != nil {
+		return nil, fmt.Errorf("reading hex input: %w", err)
+	}
+	return data, nil
+}
+
+// decodeSpans decodes data as the root struct of the schema file. The spans read before a decode error
+//	are returned with it, nil spans mean the schema could not be used.
+func decodeSpans(data []byte, file, root string, littleEndian bool) ([]hexdump.Span, error) {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	s, err := schema.Parse(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	structs := s.Structs()
+	if root == "" && len(structs) > 0 {
+		root = structs[len(structs)-1]
+	}
+	if !contains(structs, root) {
+		return nil, fmt.Errorf("%s: no struct %q declared", file, root)
+	}
+	order := binary.ByteOrder(binary.BigEndian)
+	if littleEndian {
+		order = binary.LittleEndian
+	}
+	r := bytepal.NewReaderWithOrder(data, order)
+	_, spans, err := s.DecodeSpans(r, root)
+	if err != nil {
+		return spans, fmt.Errorf("decoding %s: %w", root, err)
+	}
+	return spans, nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
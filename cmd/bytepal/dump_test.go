@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const inventory = "0102 02 1037 05 0e2b ff 00018a92 beef\n"
+
+func runDump(t *testing.T, input string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+	err := dump(args, strings.NewReader(input), &stdout, ioutil.Discard)
+	return stdout.String(), err
+}
+
+func TestDump_Schema(t *testing.T) {
+	out, err := runDump(t, inventory, "-hex", "-schema", "testdata/item.schema")
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"00000000    01 02                                            interface            u16,le     513",
+		"00000002    02                                               items                u8 prefix  2",
+		"00000003    10 37                                            items[0].id          u16        4151",
+		"00000005    05                                               items[0].amount      u8         5",
+		"00000006    0e 2b                                            items[1].id          u16        3627",
+		"00000008    ff                                               items[1].amount      u8         255",
+		"00000009    00 01 8a 92                                      items[1].big_amount  u32        101010",
+		"0000000d    be ef                                            (unconsumed, 2 bytes)",
+		"",
+	}, "\n"), out)
+
+	out, err = runDump(t, "1037 ff 00018a92", "-hex", "-le", "-schema", "testdata/item.schema", "-struct", "Item")
+	require.NoError(t, err)
+	assert.Contains(t, out, "00000000    10 37                                            id          u16  14096\n")
+	assert.Contains(t, out, "big_amount  u32  2458517760\n")
+}
+
+func TestDump_SchemaFailure(t *testing.T) {
+	out, err := runDump(t, "0102 03 1037 05 0e2b ff 00", "-hex", "-schema", "testdata/item.schema")
+	assert.EqualError(t, err, "decoding Inventory: bytepal: read of 4 bytes at offset 9, only 1 available")
+	assert.Contains(t, out, "items[1].amount  u8         255\n")
+	assert.Contains(t, out, "00000009    00                                               (unconsumed, 1 bytes)\n")
+}
+
+func TestDump_SchemaMalformed(t *testing.T) {
+	file, err := ioutil.TempFile("", "varint*.schema")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("struct P { a: uvarint }")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	out, err := runDump(t, "ffffffffffffffffffff01", "-hex", "-schema", file.Name())
+	assert.EqualError(t, err, "decoding P: bytepal: varint overflows: 64 bit varint at offset 0")
+	assert.Contains(t, out, "(unconsumed, 11 bytes)\n")
+}
+
+func TestDump_Trace(t *testing.T) {
+	out, err := runDump(t, inventory, "-hex", "-color", "-trace", "testdata/inventory.json")
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"00000000    01 02                                            interface    u16,le  513",
+		"\x1b[33m00000002    02                                               (skipped, 1 bytes)\x1b[0m",
+		"00000003    10 37                                            items[0].id  u16     4151",
+		"\x1b[31m00000005    05 0e 2b ff 00 01 8a 92 be ef                    (unconsumed, 10 bytes)\x1b[0m",
+		"",
+	}, "\n"), out)
+}
+
+func TestDump_Plain(t *testing.T) {
+	out, err := runDump(t, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "00000000  68 65 6c 6c 6f                                    |hello|\n", out)
+}
+
+func TestDump_Errors(t *testing.T) {
+	tests := map[string][]string{
+		"both":         {"-schema", "testdata/item.schema", "-trace", "testdata/inventory.json"},
+		"struct":       {"-struct", "Item"},
+		"unknown":      {"-schema", "testdata/item.schema", "-struct", "Missing"},
+		"hex":          {"-hex", "-trace", "testdata/inventory.json"},
+		"schema file":  {"-schema", "testdata/missing.schema"},
+		"bad flag":     {"-nope"},
+		"missing file": {"testdata/missing.bin"},
+	}
+	for name, args := range tests {
+		_, err := runDump(t, "zz", args...)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,41 @@
+// Bytepal is a toolbox for inspecting binary data.
+//	Usage:
+//		bytepal dump [-schema file [-struct name] | -trace file] [-hex] [-le] [-color] [file]
+//
+//	Dump prints a hexdump of a file, or stdin without one. Given a schema, the input is decoded as one of
+//	its structs and every byte range is annotated with the field name, type and value it decoded to.
+//	A trace is a JSON array of spans recorded from the Reader calls of a decode, as written by
+//	hexdump.WriteSpans for the Spans of a bytepal.Trace.
+//	Bytes after the last annotated field are marked as unconsumed, a decode failing part way exits with
+//	status 1 after printing the fields read so far.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: bytepal dump [-schema file [-struct name] | -trace file] [-hex] [-le] [-color] [file]\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "dump":
+		err = dump(os.Args[2:], os.Stdin, os.Stdout, os.Stderr)
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err == errUsage {
+		os.Exit(2)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "bytepal: %v\n", err)
+		os.Exit(1)
+	}
+}
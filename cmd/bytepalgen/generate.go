@@ -0,0 +1,658 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Pwalne/bytepal"
+)
+
+// method is a Reader or Writer method and the Go type it returns or takes.
+type method struct {
+	name, typ string
+}
+
+// encoding lists the methods used for a bytepal.Tag key, canon is the Go type of the decoded value.
+//	readT and writeT are the transform variants, if the encoding has any.
+type encoding struct {
+	canon                      string
+	read, readT, write, writeT method
+}
+
+var encodings = map[string]encoding{
+	"u8": {"uint8",
+		method{"ReadUInt8", "uint8"}, method{"ReadUInt8T", "uint8"},
+		method{"WriteUInt8", "uint8"}, method{"WriteUInt8T", "uint8"}},
+	"i8": {"int8",
+		method{"ReadInt8", "int8"}, method{"ReadInt8T", "int8"},
+		method{"WriteInt8", "int8"}, method{"WriteInt8T", "int8"}},
+	"u16": {"uint16",
+		method{"ReadUInt16", "uint16"}, method{"ReadUInt16T", "uint16"},
+		method{"WriteUInt16", "uint16"}, method{"WriteInt16T", "int16"}},
+	"i16": {"int16",
+		method{"ReadInt16", "int16"}, method{"ReadInt16T", "int16"},
+		method{"WriteInt16", "int16"}, method{"WriteInt16T", "int16"}},
+	"u16,le": {"uint16",
+		method{"ReadLEUInt16", "uint16"}, method{"ReadLEUInt16T", "uint16"},
+		method{"WriteLEUInt16", "uint16"}, method{"WriteLEInt16T", "int16"}},
+	"i16,le": {"int16",
+		method{"ReadLEInt16", "int16"}, method{"ReadLEInt16T", "int16"},
+		method{"WriteLEInt16", "int16"}, method{"WriteLEInt16T", "int16"}},
+	"u24": {"uint32",
+		method{"ReadUMedium", "uint32"}, method{},
+		method{"WriteMedium", "int32"}, method{}},
+	"i24": {"int32",
+		method{"ReadMedium", "int32"}, method{},
+		method{"WriteMedium", "int32"}, method{}},
+	"u24,le": {"uint32",
+		method{"ReadLEUMedium", "uint32"}, method{},
+		method{"WriteLEMedium", "int32"}, method{}},
+	"i24,le": {"int32",
+		method{"ReadLEMedium", "int32"}, method{},
+		method{"WriteLEMedium", "int32"}, method{}},
+	"u32": {"uint32",
+		method{"ReadUInt32", "uint32"}, method{"ReadUInt32T", "uint32"},
+		method{"WriteUInt32", "uint32"}, method{"WriteInt32T", "int32"}},
+	"i32": {"int32",
+		method{"ReadInt32", "int32"}, method{"ReadInt32T", "int32"},
+		method{"WriteInt32", "int32"}, method{"WriteInt32T", "int32"}},
+	"u32,le": {"uint32",
+		method{"ReadLEUInt32", "uint32"}, method{"ReadLEUInt32T", "uint32"},
+		method{"WriteLEUInt32", "uint32"}, method{"WriteLEInt32T", "int32"}},
+	"i32,le": {"int32",
+		method{"ReadLEInt32", "int32"}, method{"ReadLEInt32T", "int32"},
+		method{"WriteLEInt32", "int32"}, method{"WriteLEInt32T", "int32"}},
+	"u32,me": {"uint32",
+		method{"ReadMEUInt32", "uint32"}, method{},
+		method{"WriteMEUInt32", "uint32"}, method{}},
+	"i32,me": {"int32",
+		method{"ReadMEInt32", "int32"}, method{},
+		method{"WriteMEInt32", "int32"}, method{}},
+	"u32,ime": {"uint32",
+		method{"ReadIMEUInt32", "uint32"}, method{},
+		method{"WriteIMEUInt32", "uint32"}, method{}},
+	"i32,ime": {"int32",
+		method{"ReadIMEInt32", "int32"}, method{},
+		method{"WriteIMEInt32", "int32"}, method{}},
+	"u64": {"uint64",
+		method{"ReadUInt64", "uint64"}, method{},
+		method{"WriteUInt64", "uint64"}, method{}},
+	"i64": {"int64",
+		method{"ReadInt64", "int64"}, method{},
+		method{"WriteInt64", "int64"}, method{}},
+	"u64,le": {"uint64",
+		method{"ReadLEUInt64", "uint64"}, method{},
+		method{"WriteLEUInt64", "uint64"}, method{}},
+	"i64,le": {"int64",
+		method{"ReadLEInt64", "int64"}, method{},
+		method{"WriteLEInt64", "int64"}, method{}},
+	"f32": {"float32",
+		method{"ReadFloat32", "float32"}, method{},
+		method{"WriteFloat32", "float32"}, method{}},
+	"f32,le": {"float32",
+		method{"ReadLEFloat32", "float32"}, method{},
+		method{"WriteLEFloat32", "float32"}, method{}},
+	"f64": {"float64",
+		method{"ReadFloat64", "float64"}, method{},
+		method{"WriteFloat64", "float64"}, method{}},
+	"f64,le": {"float64",
+		method{"ReadLEFloat64", "float64"}, method{},
+		method{"WriteLEFloat64", "float64"}, method{}},
+	"smart": {"uint16",
+		method{"ReadSmart", "uint16"}, method{},
+		method{"WriteSmart", "uint16"}, method{}},
+	"ssmart": {"int16",
+		method{"ReadSignedSmart", "int16"}, method{},
+		method{"WriteSignedSmart", "int16"}, method{}},
+	"bigsmart": {"uint32",
+		method{"ReadBigSmartValue", "uint32"}, method{},
+		method{"WriteBigSmart", "uint32"}, method{}},
+	"nbigsmart": {"int32",
+		method{"ReadNullableBigSmart", "int32"}, method{},
+		method{"WriteNullableBigSmart", "int32"}, method{}},
+	"incrsmart": {"int",
+		method{"ReadIncrSmart", "int"}, method{},
+		method{"WriteIncrSmart", "int"}, method{}},
+	"uvarint": {"uint64",
+		method{"ReadUVarint", "uint64"}, method{},
+		method{"WriteUVarint", "uint64"}, method{}},
+	"varint": {"int64",
+		method{"ReadVarint", "int64"}, method{},
+		method{"WriteVarint", "int64"}, method{}},
+	"quic": {"uint64",
+		method{"ReadQUICVarint", "uint64"}, method{},
+		method{"WriteQUICVarint", "uint64"}, method{}},
+	"sqlite": {"uint64",
+		method{"ReadSQLiteVarint", "uint64"}, method{},
+		method{"WriteSQLiteVarint", "uint64"}, method{}},
+	"compact": {"uint64",
+		method{"ReadCompactSize", "uint64"}, method{},
+		method{"WriteCompactSize", "uint64"}, method{}},
+}
+
+// countLimits is the largest length a count encoding holds, the counts missing from it hold any length.
+var countLimits = map[string]string{
+	"u8": "255", "u16": "65535", "u24": "16777215", "u32": "4294967295",
+	"smart": "65535", "bigsmart": "4294967295", "incrsmart": "4294967295",
+	"quic": "4611686018427387903",
+}
+
+// inferred is the encoding of an untagged field by its basic type, mirroring bytepal.Marshal.
+var inferred = map[string]string{
+	"bool":    "u8",
+	"uint8":   "u8",
+	"int8":    "i8",
+	"uint16":  "u16",
+	"int16":   "i16",
+	"uint32":  "u32",
+	"int32":   "i32",
+	"uint64":  "u64",
+	"int64":   "i64",
+	"uint":    "u64",
+	"int":     "i64",
+	"float32": "f32",
+	"float64": "f64",
+	"string":  "string",
+}
+
+var transforms = map[bytepal.Transform]string{
+	bytepal.TransformA: "bytepal.TransformA",
+	bytepal.TransformC: "bytepal.TransformC",
+	bytepal.TransformS: "bytepal.TransformS",
+}
+
+// generator writes the codecs of the structs of a single package.
+type generator struct {
+	pkg string
+	// structs holds every struct declared in the package, in declaration order.
+	structs map[string]*ast.StructType
+	order   []string
+	// basics maps named types of the package to their basic underlying type.
+	basics map[string]string
+
+	buf bytes.Buffer
+	// bits is true while a run of bit fields is open.
+	bits bool
+	// depth is the nesting of loops, it picks the loop variable.
+	depth int
+}
+
+// parsePackage reads the struct and named basic types of the Go package in dir, skipping tests and
+//	the output file.
+func parsePackage(dir, output string) (*generator, error) {
+	fset := token.NewFileSet()
+	filter := func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != filepath.Base(output)
+	}
+	pkgs, err := parser.ParseDir(fset, dir, filter, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected a single package in %s, found %d", dir, len(pkgs))
+	}
+	g := &generator{
+		structs: map[string]*ast.StructType{},
+		basics:  map[string]string{},
+	}
+	for name, pkg := range pkgs {
+		g.pkg = name
+		files := make([]string, 0, len(pkg.Files))
+		for file := range pkg.Files {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			ast.Inspect(pkg.Files[file], g.collect)
+		}
+	}
+	for name, underlying := range g.basics {
+		// Resolve chains such as type A B; type B uint16.
+		for depth := 0; depth < 8 && inferred[underlying] == ""; depth++ {
+			underlying = g.basics[underlying]
+		}
+		g.basics[name] = underlying
+	}
+	return g, nil
+}
+
+func (g *generator) collect(node ast.Node) bool {
+	spec, ok := node.(*ast.TypeSpec)
+	if !ok {
+		return true
+	}
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		g.structs[spec.Name.Name] = t
+		g.order = append(g.order, spec.Name.Name)
+	case *ast.Ident:
+		g.basics[spec.Name.Name] = basicName(t.Name)
+	}
+	return false
+}
+
+func basicName(name string) string {
+	if name == "byte" {
+		return "uint8"
+	}
+	return name
+}
+
+// tagged returns the structs with at least one bytepal tag, in declaration order.
+func (g *generator) tagged() []string {
+	var names []string
+	for _, name := range g.order {
+		for _, f := range g.structs[name].Fields.List {
+			if f.Tag != nil && tagOf(f) != "" {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+func tagOf(f *ast.Field) string {
+	if f.Tag == nil {
+		return ""
+	}
+	value, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(value).Get("bytepal")
+}
+
+// generate returns the formatted source of the codecs of the given structs.
+func (g *generator) generate(names []string, args []string) ([]byte, error) {
+	g.printf("// Code generated by \"bytepalgen %s\"; DO NOT EDIT.\n\n", strings.Join(args, " "))
+	g.printf("package %s\n\nimport \"github.com/Pwalne/bytepal\"\n", g.pkg)
+	for _, name := range names {
+		s, ok := g.structs[name]
+		if !ok {
+			return nil, fmt.Errorf("struct %s not found", name)
+		}
+		if err := g.generateRead(name, s); err != nil {
+			return nil, err
+		}
+		if err := g.generateWrite(name, s); err != nil {
+			return nil, err
+		}
+	}
+	source, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v\n%s", err, g.buf.Bytes())
+	}
+	return source, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// value is a resolved field or element type.
+type value struct {
+	// goType is the type as written in the source.
+	goType string
+	// basic is the underlying basic type, empty for structs, arrays and slices.
+	basic string
+	expr  ast.Expr
+}
+
+// fields returns the encoded fields of a struct with their parsed tags.
+func (g *generator) fields(name string, s *ast.StructType) ([]*ast.Field, []bytepal.Tag, error) {
+	var fields []*ast.Field
+	var tags []bytepal.Tag
+	for _, f := range s.Fields.List {
+		tag, err := bytepal.ParseTag(tagOf(f))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", name, err)
+		}
+		if tag.Skip {
+			continue
+		}
+		if len(f.Names) == 0 {
+			return nil, nil, fmt.Errorf("%s: embedded fields are not supported", name)
+		}
+		for _, ident := range f.Names {
+			if ident.IsExported() {
+				fields = append(fields, &ast.Field{Names: []*ast.Ident{ident}, Type: f.Type})
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return fields, tags, nil
+}
+
+func (g *generator) resolve(expr ast.Expr) value {
+	v := value{goType: types.ExprString(expr), expr: expr}
+	if ident, ok := expr.(*ast.Ident); ok {
+		if _, ok := inferred[basicName(ident.Name)]; ok {
+			v.basic = basicName(ident.Name)
+		} else if basic, ok := g.basics[ident.Name]; ok {
+			v.basic = basic
+		}
+	}
+	return v
+}
+
+func (g *generator) generateRead(name string, s *ast.StructType) error {
+	fields, tags, err := g.fields(name, s)
+	if err != nil {
+		return err
+	}
+	g.printf("\n// ReadFrom decodes %s from r, it matches bytepal.Unmarshal.\n", name)
+	g.printf("func (p *%s) ReadFrom(r *bytepal.Reader) error {\n", name)
+	if usesBits(tags) {
+		g.printf("var bits func(uint) uint\n")
+	}
+	g.bits, g.depth = false, 0
+	for i, f := range fields {
+		if err := g.read("p."+f.Names[0].Name, f.Type, tags[i]); err != nil {
+			return fmt.Errorf("%s.%s: %v", name, f.Names[0].Name, err)
+		}
+	}
+	g.printf("return r.Err()\n}\n")
+	return nil
+}
+
+func (g *generator) generateWrite(name string, s *ast.StructType) error {
+	fields, tags, err := g.fields(name, s)
+	if err != nil {
+		return err
+	}
+	g.printf("\n// WriteTo encodes %s to w, it matches bytepal.Marshal but only range checks counts.\n", name)
+	g.printf("func (p *%s) WriteTo(w bytepal.Writer) error {\n", name)
+	if usesBits(tags) {
+		g.printf("var bits func(uint, uint)\n")
+	}
+	g.bits, g.depth = false, 0
+	for i, f := range fields {
+		if err := g.write("p."+f.Names[0].Name, f.Type, tags[i]); err != nil {
+			return fmt.Errorf("%s.%s: %v", name, f.Names[0].Name, err)
+		}
+	}
+	g.printf("return w.Err()\n}\n")
+	return nil
+}
+
+func usesBits(tags []bytepal.Tag) bool {
+	for _, tag := range tags {
+		if tag.Type == "bits" {
+			return true
+		}
+	}
+	return false
+}
+
+// loopVar returns the index variable of the next nested loop.
+func (g *generator) loopVar() string {
+	return string(rune('i' + g.depth))
+}
+
+// isBits reports whether the elements of expr are bit fields, they share a run opened before the loop.
+func isBits(expr ast.Expr, tag bytepal.Tag) bool {
+	if array, ok := expr.(*ast.ArrayType); ok && array.Len != nil {
+		return isBits(array.Elt, tag)
+	}
+	return tag.Type == "bits"
+}
+
+func (g *generator) read(path string, expr ast.Expr, tag bytepal.Tag) error {
+	v := g.resolve(expr)
+	switch t := expr.(type) {
+	case *ast.ArrayType:
+		elemTag := tag
+		elemTag.Count = ""
+		elem := g.resolve(t.Elt)
+		if t.Len == nil {
+			if tag.Count == "" {
+				return fmt.Errorf("slice needs a count")
+			}
+			g.bits = false
+			count := encodings[tag.Count]
+			n := fmt.Sprintf("r.Count(uint64(r.%s()))", count.read.name)
+			if isBytes(elem, elemTag) {
+				g.printf("%s = append([]byte{}, r.ReadSlice(%s)...)\n", path, n)
+				return nil
+			}
+			// The slice grows with its elements, so a count the input can not back allocates nothing.
+			g.openReadBits(isBits(t.Elt, elemTag))
+			i := g.loopVar()
+			g.printf("%s = %s{}\n", path, v.goType)
+			g.printf("for %s, n := 0, %s; %s < n; %s++ {\n", i, n, i, i)
+			g.printf("var v%s %s\n", i, elem.goType)
+			g.depth++
+			err := g.read("v"+i, t.Elt, elemTag)
+			g.depth--
+			if _, nested := t.Elt.(*ast.ArrayType); nested || elem.basic != "" {
+				// A struct element already returned its error.
+				g.printf("if err := r.Err(); err != nil {\nreturn err\n}\n")
+			}
+			g.printf("%s = append(%s, v%s)\n}\n", path, path, i)
+			return err
+		} else {
+			if tag.Count != "" {
+				return fmt.Errorf("array can not have a count")
+			}
+			if isBytes(elem, elemTag) {
+				g.bits = false
+				g.printf("r.ReadBytes(%s[:])\n", path)
+				return nil
+			}
+		}
+		g.openReadBits(isBits(t.Elt, elemTag))
+		i := g.loopVar()
+		g.printf("for %s := range %s {\n", i, path)
+		g.depth++
+		err := g.read(path+"["+i+"]", t.Elt, elemTag)
+		g.depth--
+		g.printf("}\n")
+		return err
+	}
+	if tag.Count != "" {
+		return fmt.Errorf("%s can not have a count", v.goType)
+	}
+	if v.basic == "" {
+		if err := g.checkStruct(v, tag); err != nil {
+			return err
+		}
+		g.bits = false
+		g.printf("if err := %s.ReadFrom(r); err != nil {\nreturn err\n}\n", path)
+		return nil
+	}
+	key, err := leafKey(v, tag)
+	if err != nil {
+		return err
+	}
+	switch key {
+	case "bits":
+		g.openReadBits(true)
+		g.printf("%s = %s\n", path, convert(v, "uint", fmt.Sprintf("bits(%d)", tag.Bits)))
+		return nil
+	case "string":
+		g.bits = false
+		g.printf("%s = %s\n", path, convert(v, "string", fmt.Sprintf("r.ReadString(%d)", tag.Delim)))
+		return nil
+	}
+	g.bits = false
+	enc := encodings[key]
+	m, arg := enc.read, ""
+	if tag.Transform != bytepal.TransformNone {
+		m, arg = enc.readT, transforms[tag.Transform]
+	}
+	call := fmt.Sprintf("r.%s(%s)", m.name, arg)
+	if m.typ != enc.canon {
+		call = enc.canon + "(" + call + ")"
+	}
+	g.printf("%s = %s\n", path, convert(v, enc.canon, call))
+	return nil
+}
+
+func (g *generator) write(path string, expr ast.Expr, tag bytepal.Tag) error {
+	v := g.resolve(expr)
+	switch t := expr.(type) {
+	case *ast.ArrayType:
+		elemTag := tag
+		elemTag.Count = ""
+		elem := g.resolve(t.Elt)
+		if t.Len == nil {
+			if tag.Count == "" {
+				return fmt.Errorf("slice needs a count")
+			}
+			g.bits = false
+			count := encodings[tag.Count]
+			n := "len(" + path + ")"
+			if limit, ok := countLimits[tag.Count]; ok {
+				g.printf("if uint64(%s) > %s {\nreturn bytepal.ErrRange\n}\n", n, limit)
+			}
+			if count.write.typ != "int" {
+				n = count.write.typ + "(" + n + ")"
+			}
+			g.printf("w.%s(%s)\n", count.write.name, n)
+			if isBytes(elem, elemTag) {
+				g.printf("w.Write(%s)\n", path)
+				return nil
+			}
+		} else {
+			if tag.Count != "" {
+				return fmt.Errorf("array can not have a count")
+			}
+			if isBytes(elem, elemTag) {
+				g.bits = false
+				g.printf("w.Write(%s[:])\n", path)
+				return nil
+			}
+		}
+		g.openWriteBits(isBits(t.Elt, elemTag))
+		i := g.loopVar()
+		g.printf("for %s := range %s {\n", i, path)
+		g.depth++
+		err := g.write(path+"["+i+"]", t.Elt, elemTag)
+		g.depth--
+		g.printf("}\n")
+		return err
+	}
+	if tag.Count != "" {
+		return fmt.Errorf("%s can not have a count", v.goType)
+	}
+	if v.basic == "" {
+		if err := g.checkStruct(v, tag); err != nil {
+			return err
+		}
+		g.bits = false
+		g.printf("if err := %s.WriteTo(w); err != nil {\nreturn err\n}\n", path)
+		return nil
+	}
+	key, err := leafKey(v, tag)
+	if err != nil {
+		return err
+	}
+	switch key {
+	case "bits":
+		g.openWriteBits(true)
+		g.writeCall(v, path, fmt.Sprintf("bits(%d, %%s)", tag.Bits), "uint")
+		return nil
+	case "string":
+		g.bits = false
+		g.writeCall(v, path, fmt.Sprintf("w.WriteString(%%s, %d)", tag.Delim), "string")
+		return nil
+	}
+	g.bits = false
+	enc := encodings[key]
+	m, arg := enc.write, ""
+	if tag.Transform != bytepal.TransformNone {
+		m, arg = enc.writeT, ", "+transforms[tag.Transform]
+	}
+	g.writeCall(v, path, "w."+m.name+"(%s"+arg+")", m.typ)
+	return nil
+}
+
+// writeCall prints the call format with path converted to typ, bools are written as 1 or 0.
+func (g *generator) writeCall(v value, path, format, typ string) {
+	if v.basic == "bool" {
+		g.printf("if %s {\n"+format+"\n} else {\n"+format+"\n}\n", path, "1", "0")
+		return
+	}
+	if v.goType != typ {
+		path = typ + "(" + path + ")"
+	}
+	g.printf(format+"\n", path)
+}
+
+// checkStruct checks v is a struct of the package or a type of another package, which is assumed to
+//	have generated codecs.
+func (g *generator) checkStruct(v value, tag bytepal.Tag) error {
+	switch t := v.expr.(type) {
+	case *ast.Ident:
+		if _, ok := g.structs[t.Name]; !ok {
+			return fmt.Errorf("unsupported type %s", v.goType)
+		}
+	case *ast.SelectorExpr:
+	default:
+		return fmt.Errorf("unsupported type %s", v.goType)
+	}
+	if tag.Type != "" {
+		return fmt.Errorf("struct %s can not be tagged %q", v.goType, tag.Key())
+	}
+	return nil
+}
+
+func (g *generator) openReadBits(open bool) {
+	if open && !g.bits {
+		g.printf("bits = r.ReadBits()\n")
+	}
+	g.bits = open
+}
+
+func (g *generator) openWriteBits(open bool) {
+	if open && !g.bits {
+		g.printf("bits = w.BitAccess()\n")
+	}
+	g.bits = open
+}
+
+// convert returns the expression call of type from converted to the type of v.
+func convert(v value, from, call string) string {
+	switch {
+	case v.basic == "bool":
+		return call + " != 0"
+	case v.goType == from:
+		return call
+	}
+	return v.goType + "(" + call + ")"
+}
+
+func isBytes(v value, tag bytepal.Tag) bool {
+	return (v.goType == "byte" || v.goType == "uint8") && (tag.Type == "" || tag.Type == "u8") &&
+		tag.Transform == bytepal.TransformNone
+}
+
+// leafKey returns the encoding key of a basic value, checking it suits the Go type like bytepal.Marshal.
+func leafKey(v value, tag bytepal.Tag) (string, error) {
+	if tag.Type == "" {
+		tag.Type = inferred[v.basic]
+	}
+	key := tag.Key()
+	isString := v.basic == "string"
+	isFloat := v.basic == "float32" || v.basic == "float64"
+	switch {
+	case key == "string" && isString, key == "bits" && !isString && !isFloat:
+	case strings.HasPrefix(key, "f") && isFloat:
+	case encodings[key].read.name != "" && !isString && !isFloat && !strings.HasPrefix(key, "f"):
+	default:
+		return "", fmt.Errorf("%s can not be encoded as %q", v.goType, key)
+	}
+	return key, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func TestGenerate_Golden(t *testing.T) {
+	dir := filepath.Join("testdata", "packets")
+	golden := filepath.Join(dir, "packets_bytepal.go.golden")
+	g, err := parsePackage(dir, "packets_bytepal.go")
+	require.NoError(t, err)
+	source, err := g.generate([]string{"Position", "Login", "Update"}, []string{"-type", "Position,Login,Update"})
+	require.NoError(t, err)
+
+	if *update {
+		require.NoError(t, ioutil.WriteFile(golden, source, 0644))
+	}
+	expected, err := ioutil.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(source))
+
+	// The generated methods must compile against the bytepal package.
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for name, src := range map[string]interface{}{"packets.go": nil, "packets_bytepal.go": source} {
+		path := name
+		if src == nil {
+			path = filepath.Join(dir, name)
+		}
+		file, err := parser.ParseFile(fset, path, src, 0)
+		require.NoError(t, err)
+		files = append(files, file)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("packets", fset, files, nil)
+	assert.NoError(t, err)
+}
+
+// TestGenerate_RoundTrip runs testdata/packets/roundtrip_test.go against freshly generated methods, they
+//	must produce and consume the same bytes as bytepal.Marshal and bytepal.Unmarshal.
+func TestGenerate_RoundTrip(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil || testing.Short() {
+		t.Skip("needs the go command")
+	}
+	src := filepath.Join("testdata", "packets")
+	g, err := parsePackage(src, "packets_bytepal.go")
+	require.NoError(t, err)
+	source, err := g.generate(g.tagged(), nil)
+	require.NoError(t, err)
+
+	// The copy stays inside the module so that it builds against this version of bytepal.
+	dir, err := ioutil.TempDir("testdata", "roundtrip")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"packets.go", "roundtrip_test.go"} {
+		data, err := ioutil.ReadFile(filepath.Join(src, name))
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), data, 0644))
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "packets_bytepal.go"), source, 0644))
+
+	out, err := exec.Command(goTool, "test", "./"+filepath.ToSlash(dir)).CombinedOutput()
+	assert.NoError(t, err, "%s", out)
+}
+
+func TestGenerate_Tagged(t *testing.T) {
+	g, err := parsePackage(filepath.Join("testdata", "packets"), "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Position", "Login", "Update"}, g.tagged())
+}
+
+func TestGenerate_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"slice without count": "type T struct { A []uint16 }",
+		"array with count":    "type T struct { A [2]uint16 `bytepal:\"count=u8\"` }",
+		"string as integer":   "type T struct { A string `bytepal:\"u16\"` }",
+		"integer as string":   "type T struct { A uint16 `bytepal:\"string\"` }",
+		"float as integer":    "type T struct { A float32 `bytepal:\"u32\"` }",
+		"tagged struct":       "type T struct { A S `bytepal:\"u8\"` }\ntype S struct{}",
+		"unknown type":        "type T struct { A map[int]int }",
+		"bad tag":             "type T struct { A uint8 `bytepal:\"u8,le\"` }",
+	}
+	for name, source := range tests {
+		dir, err := ioutil.TempDir("", "bytepalgen")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "t.go"), []byte("package t\n"+source), 0644))
+		g, err := parsePackage(dir, "")
+		require.NoError(t, err, name)
+		_, err = g.generate([]string{"T"}, nil)
+		assert.Error(t, err, name)
+	}
+}
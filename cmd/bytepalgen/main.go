@@ -0,0 +1,75 @@
+// Bytepalgen generates allocation free ReadFrom and WriteTo methods for structs with bytepal tags.
+//	The generated methods call the Reader and Writer directly and encode exactly like bytepal.Unmarshal
+//	and bytepal.Marshal, except that values are converted without range checks. Slice counts are checked,
+//	a slice too long for its count returns bytepal.ErrRange.
+//
+//	Usage, typically from a go:generate directive in the package of the structs:
+//		//go:generate bytepalgen -type Login,Logout
+//
+//	Without -type every struct with at least one bytepal tag is generated. Structs nested in a generated
+//	struct must have generated methods too.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma separated list of struct names, all tagged structs if empty")
+	output    = flag.String("output", "", "output file name, <type>_bytepal.go or bytepal_gen.go by default")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: bytepalgen [-type T1,T2] [-output file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	var names []string
+	if *typeNames != "" {
+		names = strings.Split(*typeNames, ",")
+	}
+	out := *output
+	if out == "" {
+		out = "bytepal_gen.go"
+		if len(names) > 0 {
+			out = strings.ToLower(names[0]) + "_bytepal.go"
+		}
+	}
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(dir, out)
+	}
+
+	if err := run(dir, out, names, os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "bytepalgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, out string, names, args []string) error {
+	g, err := parsePackage(dir, out)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		names = g.tagged()
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no structs with bytepal tags in %s", dir)
+	}
+	source, err := g.generate(names, args)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(out, source, 0644)
+}
@@ -0,0 +1,40 @@
+package packets
+
+//go:generate bytepalgen -type Position,Login,Update
+
+// Rights is a named basic type, it is encoded like its underlying type.
+type Rights uint8
+
+type Position struct {
+	X     uint16 `bytepal:"bits=14"`
+	Y     uint16 `bytepal:"bits=14"`
+	Plane uint8  `bytepal:"bits=2"`
+	Run   bool   `bytepal:"bits=1"`
+}
+
+type Login struct {
+	Opcode  uint8
+	Size    uint16 `bytepal:"u16,le"`
+	Version uint32 `bytepal:"u24"`
+	Rights  Rights `bytepal:"u8,t=a"`
+	Low     bool
+	Name    string `bytepal:"string,delim=10"`
+	Seeds   [4]int32
+	Keys    [2]uint32 `bytepal:"u32,ime"`
+	Session int64     `bytepal:"i64,le"`
+	Ignored int       `bytepal:"-"`
+	private int
+}
+
+type Update struct {
+	Item     uint32   `bytepal:"bigsmart"`
+	Amount   int      `bytepal:"ssmart"`
+	Offset   int32    `bytepal:"i32,le,t=s"`
+	Flags    [3]uint8 `bytepal:"bits=3"`
+	Position Position
+	Path     []Position  `bytepal:"count=u8"`
+	Payload  []byte      `bytepal:"count=smart"`
+	Levels   []int16     `bytepal:"i16,count=uvarint"`
+	Scale    float64     `bytepal:"f32,le"`
+	Grid     [2][2]uint8 `bytepal:"u8,t=c"`
+}
@@ -0,0 +1,92 @@
+package packets
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Pwalne/bytepal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRoundTrip is run by TestGenerate_RoundTrip next to freshly generated methods.
+
+type generated interface {
+	ReadFrom(r *bytepal.Reader) error
+	WriteTo(w bytepal.Writer) error
+}
+
+func TestRoundTrip(t *testing.T) {
+	values := []generated{
+		&Position{X: 3200, Y: 16383, Plane: 3, Run: true},
+		&Login{
+			Opcode:  16,
+			Size:    0x1234,
+			Version: 0xABCDEF,
+			Rights:  2,
+			Low:     true,
+			Name:    "zezima",
+			Seeds:   [4]int32{1, -2, 1 << 30, -1 << 31},
+			Keys:    [2]uint32{0x0A0B0C0D, 0xFFFFFFFF},
+			Session: -1234567890123,
+		},
+		&Update{
+			Item:     70000,
+			Amount:   -300,
+			Offset:   -5,
+			Flags:    [3]uint8{1, 7, 0},
+			Position: Position{X: 1, Y: 2, Plane: 1},
+			Path:     []Position{{X: 10, Y: 20, Run: true}, {X: 16383, Plane: 2}},
+			Payload:  []byte{1, 2, 3},
+			Levels:   []int16{-1, 99, 32767},
+			Scale:    1.5,
+			Grid:     [2][2]uint8{{1, 2}, {3, 255}},
+		},
+		// Decoded slices are empty rather than nil.
+		&Update{Path: []Position{}, Payload: []byte{}, Levels: []int16{}},
+	}
+	for _, v := range values {
+		name := reflect.TypeOf(v).Elem().Name()
+		marshalled := bytepal.NewExpandableWriter()
+		require.NoError(t, bytepal.Marshal(v, marshalled), name)
+		written := bytepal.NewExpandableWriter()
+		require.NoError(t, v.WriteTo(written), name)
+		assert.Equal(t, marshalled.Payload(), written.Payload(), name)
+
+		read := reflect.New(reflect.TypeOf(v).Elem()).Interface().(generated)
+		r := bytepal.NewCheckedReader(marshalled.Payload())
+		require.NoError(t, read.ReadFrom(r), name)
+		assert.Equal(t, 0, r.Remaining(), name)
+		assert.Equal(t, v, read, name)
+
+		unmarshalled := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+		r = bytepal.NewCheckedReader(written.Payload())
+		require.NoError(t, bytepal.Unmarshal(r, unmarshalled), name)
+		assert.Equal(t, 0, r.Remaining(), name)
+		assert.Equal(t, v, unmarshalled, name)
+	}
+}
+
+func TestWriteTo_CountRange(t *testing.T) {
+	w := bytepal.NewExpandableWriter()
+	err := (&Update{Path: make([]Position, 256)}).WriteTo(w)
+	assert.True(t, errors.Is(err, bytepal.ErrRange))
+	require.NoError(t, (&Update{Path: make([]Position, 255)}).WriteTo(w))
+}
+
+func TestReadFrom_CountAllocation(t *testing.T) {
+	// A count of 32768 levels backed by 4096 bytes grows the slice with the levels read instead of up front.
+	empty := bytepal.NewExpandableWriter()
+	require.NoError(t, (&Update{}).WriteTo(empty))
+	w := bytepal.NewExpandableWriter()
+	// Everything before the levels, whose count, scale and grid take the last 9 bytes.
+	w.Write(empty.Payload()[:empty.Size()-9])
+	w.WriteUVarint(32768)
+	w.Write(make([]byte, 4096))
+	var u Update
+	r := bytepal.NewCheckedReader(w.Payload())
+	assert.Error(t, u.ReadFrom(r))
+	assert.Equal(t, 2048, len(u.Levels))
+	assert.Less(t, cap(u.Levels), 4096)
+}
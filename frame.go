@@ -0,0 +1,163 @@
+package bytepal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// FrameVarByte is the size of an opcode whose body is prefixed by its length as a byte.
+	FrameVarByte = -1
+	// FrameVarShort is the size of an opcode whose body is prefixed by its length as a big endian short.
+	FrameVarShort = -2
+)
+
+var (
+	// ErrNeedMore is returned when a Reader ends before the next frame is complete.
+	ErrNeedMore = errors.New("bytepal: need more data")
+	// ErrUnknownOpcode is returned for an opcode that is missing from the size table.
+	ErrUnknownOpcode = errors.New("bytepal: unknown opcode")
+	// ErrFrameSize is returned when a body does not fit the size its opcode allows.
+	ErrFrameSize = errors.New("bytepal: invalid frame size")
+)
+
+// Framer writes and splits packets made of an opcode followed by a body that is either a fixed size
+//	or prefixed by its length as a byte or short.
+type Framer struct {
+	// Sizes holds the body size of each opcode, or FrameVarByte and FrameVarShort.
+	Sizes map[uint8]int
+	// Cipher encrypts the opcodes when it is not nil.
+	Cipher Cipher
+
+	// pending holds the opcode of an incomplete frame, the cipher must not be advanced twice for it.
+	pending bool
+	opcode  uint8
+}
+
+// NewFramer creates a Framer using the given size table and an optional opcode cipher.
+func NewFramer(sizes map[uint8]int, cipher Cipher) *Framer {
+	return &Framer{
+		Sizes:  sizes,
+		Cipher: cipher,
+	}
+}
+
+// WriteFrame writes opcode with the header its size requires followed by the payload of body.
+func (f *Framer) WriteFrame(out Writer, opcode uint8, body Writer) error {
+	size, ok := f.Sizes[opcode]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrUnknownOpcode, opcode)
+	}
+	if err := body.Err(); err != nil {
+		return err
+	}
+	length := body.Size()
+	switch {
+	case size == FrameVarByte && length > 0xFF,
+		size == FrameVarShort && length > 0xFFFF,
+		size >= 0 && length != size:
+		return fmt.Errorf("%w: opcode %d with %d bytes", ErrFrameSize, opcode, length)
+	}
+
+	out.WriteOpcode(f.Cipher, opcode)
+	switch size {
+	case FrameVarByte:
+		out.WriteUInt8(uint8(length))
+	case FrameVarShort:
+		// The length is big endian regardless of the byte order of out.
+		var prefix [2]byte
+		binary.BigEndian.PutUint16(prefix[:], uint16(length))
+		out.Write(prefix[:])
+	}
+	out.Write(body.Payload())
+	return out.Err()
+}
+
+// Next reads the next complete frame off in and returns its opcode and body.
+//	If the frame is incomplete ErrNeedMore is returned and in is left at the start of the frame,
+//	the call can be repeated once more data is available.
+func (f *Framer) Next(in *Reader) (uint8, *Reader, error) {
+	start := in.currentIndex
+	if in.Remaining() < 1 {
+		return 0, nil, ErrNeedMore
+	}
+	opcode := f.opcode
+	if f.pending {
+		in.currentIndex++
+	} else {
+		opcode = in.ReadOpcode(f.Cipher)
+	}
+	size, ok := f.Sizes[opcode]
+	if !ok {
+		f.pending = false
+		return 0, nil, fmt.Errorf("%w: %d", ErrUnknownOpcode, opcode)
+	}
+
+	header := 0
+	switch size {
+	case FrameVarByte:
+		header = 1
+	case FrameVarShort:
+		header = 2
+	}
+	if in.Remaining() >= header {
+		switch size {
+		case FrameVarByte:
+			size = int(in.ReadUInt8())
+		case FrameVarShort:
+			size = int(binary.BigEndian.Uint16(in.take(2)))
+		}
+		if in.Remaining() >= size {
+			f.pending = false
+			return opcode, in.derive(in.ReadSlice(size)), nil
+		}
+	}
+	f.pending, f.opcode = true, opcode
+	in.currentIndex = start
+	return 0, nil, ErrNeedMore
+}
+
+// ReadFrame reads the next frame from r, blocking until it is complete.
+//	io.EOF is returned if r ends before a frame starts and io.ErrUnexpectedEOF if it ends within one.
+func (f *Framer) ReadFrame(r io.Reader) (uint8, *Reader, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:1]); err != nil {
+		return 0, nil, err
+	}
+	opcode := header[0]
+	if f.Cipher != nil {
+		opcode -= uint8(f.Cipher.NextInt())
+	}
+	size, ok := f.Sizes[opcode]
+	if !ok {
+		return 0, nil, fmt.Errorf("%w: %d", ErrUnknownOpcode, opcode)
+	}
+
+	switch size {
+	case FrameVarByte:
+		if _, err := io.ReadFull(r, header[:1]); err != nil {
+			return 0, nil, noEOF(err)
+		}
+		size = int(header[0])
+	case FrameVarShort:
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return 0, nil, noEOF(err)
+		}
+		size = int(binary.BigEndian.Uint16(header[:]))
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, noEOF(err)
+	}
+	return opcode, NewReader(body), nil
+}
+
+// noEOF turns io.EOF into io.ErrUnexpectedEOF, for reads that started inside a frame.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
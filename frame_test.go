@@ -0,0 +1,130 @@
+package bytepal
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+var frameSizes = map[uint8]int{
+	1: 4,
+	2: FrameVarByte,
+	3: FrameVarShort,
+	4: 0,
+}
+
+func writeFrames(t *testing.T, f *Framer) []byte {
+	out := NewExpandableWriter()
+
+	body := NewExpandableWriter()
+	body.WriteInt32(0x01020304)
+	require.NoError(t, f.WriteFrame(out, 1, body))
+
+	body = NewExpandableWriter()
+	body.WriteString("hello", 0)
+	require.NoError(t, f.WriteFrame(out, 2, body))
+
+	body = NewExpandableWriter()
+	body.Write(make([]byte, 300))
+	require.NoError(t, f.WriteFrame(out, 3, body))
+
+	require.NoError(t, f.WriteFrame(out, 4, NewExpandableWriter()))
+	return out.Payload()
+}
+
+func TestFramer_WriteFrame(t *testing.T) {
+	data := writeFrames(t, NewFramer(frameSizes, nil))
+	assert.Equal(t, []byte{1, 1, 2, 3, 4}, data[:5])
+	assert.Equal(t, []byte{2, 6, 'h', 'e', 'l', 'l', 'o', 0}, data[5:13])
+	assert.Equal(t, []byte{3, 0x01, 0x2C}, data[13:16])
+	assert.Equal(t, []byte{4}, data[316:])
+
+	f := NewFramer(frameSizes, nil)
+	out := NewExpandableWriter()
+	body := NewExpandableWriter()
+	body.WriteInt16(1)
+	assert.True(t, errors.Is(f.WriteFrame(out, 1, body), ErrFrameSize))
+	assert.True(t, errors.Is(f.WriteFrame(out, 9, body), ErrUnknownOpcode))
+	body.Write(make([]byte, 300))
+	assert.True(t, errors.Is(f.WriteFrame(out, 2, body), ErrFrameSize))
+	assert.Empty(t, out.Payload())
+}
+
+func testNextFrames(t *testing.T, f *Framer, next func() (uint8, *Reader, error)) {
+	opcode, body, err := next()
+	require.NoError(t, err)
+	assert.Equal(t, uint8(1), opcode)
+	assert.Equal(t, uint32(0x01020304), body.ReadUInt32())
+
+	opcode, body, err = next()
+	require.NoError(t, err)
+	assert.Equal(t, uint8(2), opcode)
+	assert.Equal(t, "hello", body.ReadString(0))
+
+	opcode, body, err = next()
+	require.NoError(t, err)
+	assert.Equal(t, uint8(3), opcode)
+	assert.Equal(t, 300, body.Remaining())
+
+	opcode, body, err = next()
+	require.NoError(t, err)
+	assert.Equal(t, uint8(4), opcode)
+	assert.Equal(t, 0, body.Remaining())
+}
+
+func TestFramer_Next(t *testing.T) {
+	for _, seed := range [][]uint32{nil, {1, 2, 3, 4}} {
+		var cipher Cipher
+		if seed != nil {
+			cipher = NewISAAC(seed)
+		}
+		data := writeFrames(t, NewFramer(frameSizes, cipher))
+
+		if seed != nil {
+			cipher = NewISAAC(seed)
+		}
+		f := NewFramer(frameSizes, cipher)
+		// Feed the frames a byte at a time, every incomplete frame must ask for more data.
+		in := NewReader(data[:0])
+		opcodes, bodies := make([]uint8, 0), make([]*Reader, 0)
+		for i := 1; i <= len(data); i++ {
+			position := in.currentIndex
+			in = NewReader(data[:i])
+			in.SetCurrentRead(position)
+			opcode, body, err := f.Next(in)
+			if err == ErrNeedMore {
+				continue
+			}
+			require.NoError(t, err)
+			opcodes, bodies = append(opcodes, opcode), append(bodies, body)
+		}
+		require.Len(t, opcodes, 4)
+		testNextFrames(t, f, func() (uint8, *Reader, error) {
+			opcode, body := opcodes[0], bodies[0]
+			opcodes, bodies = opcodes[1:], bodies[1:]
+			return opcode, body, nil
+		})
+		_, _, err := f.Next(in)
+		assert.Equal(t, ErrNeedMore, err)
+	}
+}
+
+func TestFramer_ReadFrame(t *testing.T) {
+	cipher := NewISAAC([]uint32{4, 3, 2, 1})
+	data := writeFrames(t, NewFramer(frameSizes, cipher))
+
+	f := NewFramer(frameSizes, NewISAAC([]uint32{4, 3, 2, 1}))
+	r := bytes.NewReader(data)
+	testNextFrames(t, f, func() (uint8, *Reader, error) { return f.ReadFrame(r) })
+	_, _, err := f.ReadFrame(r)
+	assert.Equal(t, io.EOF, err)
+
+	f = NewFramer(frameSizes, nil)
+	_, _, err = f.ReadFrame(bytes.NewReader([]byte{3, 0, 5, 1}))
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	_, _, err = f.ReadFrame(bytes.NewReader([]byte{9}))
+	assert.True(t, errors.Is(err, ErrUnknownOpcode))
+}
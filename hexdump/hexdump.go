@@ -0,0 +1,211 @@
+// Package hexdump renders bytes as a hexdump annotated with the fields decoded from them.
+//	Spans come from a schema decode or a recorded trace, and can be stored as JSON in between:
+//
+//		[{"offset": 0, "size": 2, "name": "id", "type": "u16", "value": 4151}]
+//
+//	Bytes read by no span are marked as skipped, and the bytes after the last span as unconsumed.
+package hexdump
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// BytesPerLine is the number of bytes shown on each line of a dump.
+const BytesPerLine = 16
+
+// Span annotates the bytes of a single decoded value.
+type Span struct {
+	Offset int `json:"offset"`
+	Size   int `json:"size"`
+	// Bit and Bits locate a bit field within the bytes of the span, Bits is 0 for byte values.
+	Bit   int         `json:"bit,omitempty"`
+	Bits  int         `json:"bits,omitempty"`
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// End returns the offset of the byte after the span.
+func (s Span) End() int {
+	return s.Offset + s.Size
+}
+
+// Options control how Annotate renders a dump.
+type Options struct {
+	// Color highlights the skipped and unconsumed bytes with ANSI escape codes.
+	Color bool
+}
+
+const (
+	colorSkipped    = "\x1b[33m"
+	colorUnconsumed = "\x1b[31m"
+	colorReset      = "\x1b[0m"
+)
+
+// ReadSpans decodes a JSON array of spans. Numbers are kept as json.Number so large values print exactly.
+//	Spans with a negative offset or size are rejected.
+func ReadSpans(r io.Reader) ([]Span, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var spans []Span
+	if err := decoder.Decode(&spans); err != nil {
+		return nil, fmt.Errorf("hexdump: reading spans: %w", err)
+	}
+	if err := validate(spans); err != nil {
+		return nil, err
+	}
+	return spans, nil
+}
+
+// validate rejects spans that do not locate any bytes.
+func validate(spans []Span) error {
+	for _, span := range spans {
+		if span.Offset < 0 || span.Size < 0 {
+			return fmt.Errorf("hexdump: span %q has offset %d and size %d", span.Name, span.Offset, span.Size)
+		}
+	}
+	return nil
+}
+
+// WriteSpans encodes spans as a JSON array, one span per line.
+func WriteSpans(w io.Writer, spans []Span) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, span := range spans {
+		line, err := json.Marshal(span)
+		if err != nil {
+			return err
+		}
+		sep := ",\n"
+		if i == 0 {
+			sep = "\n"
+		}
+		if _, err := fmt.Fprintf(w, "%s  %s", sep, line); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n]\n")
+	return err
+}
+
+// Annotate writes a dump of data with one entry per span, in order of offset. Values longer than a line
+//	continue on the following lines. Gaps between spans are shown as skipped and the bytes after the
+//	last span as unconsumed, spans past the end of data are listed without bytes. Spans with a negative
+//	offset or size return an error before anything is written.
+func Annotate(w io.Writer, data []byte, spans []Span, opts Options) error {
+	if err := validate(spans); err != nil {
+		return err
+	}
+	spans = append([]Span(nil), spans...)
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].Offset != spans[j].Offset {
+			return spans[i].Offset < spans[j].Offset
+		}
+		return spans[i].Bit < spans[j].Bit
+	})
+
+	d := &dumper{w: w, data: data, opts: opts}
+	for _, span := range spans {
+		if width := len(span.Name); width > d.nameWidth {
+			d.nameWidth = width
+		}
+		if width := len(span.Type); width > d.typeWidth {
+			d.typeWidth = width
+		}
+	}
+
+	end := 0
+	for _, span := range spans {
+		if span.Offset > end {
+			d.gap(end, span.Offset, "skipped", colorSkipped)
+		}
+		d.span(span)
+		if span.End() > end {
+			end = span.End()
+		}
+	}
+	if end < len(data) {
+		d.gap(end, len(data), "unconsumed", colorUnconsumed)
+	}
+	return d.err
+}
+
+type dumper struct {
+	w    io.Writer
+	data []byte
+	opts Options
+	// nameWidth and typeWidth align the annotations of every span.
+	nameWidth int
+	typeWidth int
+	err       error
+}
+
+func (d *dumper) span(span Span) {
+	offset := fmt.Sprintf("%08x", span.Offset)
+	if span.Bits > 0 {
+		offset += fmt.Sprintf(".%d", span.Bit)
+	}
+	annotation := fmt.Sprintf("%-*s  %-*s  %s", d.nameWidth, span.Name, d.typeWidth, span.Type, FormatValue(span.Value))
+	d.rows(offset, span.Offset, span.End(), annotation, "")
+}
+
+func (d *dumper) gap(start, end int, label, color string) {
+	annotation := fmt.Sprintf("(%s, %d bytes)", label, end-start)
+	if !d.opts.Color {
+		color = ""
+	}
+	d.rows(fmt.Sprintf("%08x", start), start, end, annotation, color)
+}
+
+// rows writes the bytes from start to end, annotating the first line and labelling it with offset.
+func (d *dumper) rows(offset string, start, end int, annotation, color string) {
+	if end > len(d.data) {
+		end = len(d.data)
+	}
+	for first := true; first || start < end; first = false {
+		next := start + BytesPerLine
+		if next > end {
+			next = end
+		}
+		var hex []string
+		for i := start; i < next; i++ {
+			hex = append(hex, fmt.Sprintf("%02x", d.data[i]))
+		}
+		label := ""
+		if first {
+			label = annotation
+		} else {
+			offset = fmt.Sprintf("%08x", start)
+		}
+		line := strings.TrimRight(fmt.Sprintf("%-10s  %-*s  %s", offset, BytesPerLine*3-1, strings.Join(hex, " "), label), " ")
+		if color != "" {
+			line = color + line + colorReset
+		}
+		d.println(line)
+		start = next
+	}
+}
+
+func (d *dumper) println(line string) {
+	if d.err == nil {
+		_, d.err = fmt.Fprintln(d.w, line)
+	}
+}
+
+// FormatValue formats a value of a span, strings are quoted so empty and padded values stay visible.
+func FormatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []byte:
+		return fmt.Sprintf("% x", v)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,75 @@
+package hexdump
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotate(t *testing.T) {
+	data := []byte("\x10\x37\xc0\x00\x00\x00abcdefghijklmnopqrstuvwxyz\x00\xde\xad")
+	spans := []Span{
+		{Offset: 6, Size: 27, Name: "name", Type: "string", Value: "abcdefghijklmnopqrstuvwxyz"},
+		{Offset: 0, Size: 2, Name: "id", Type: "u16", Value: uint64(4151)},
+		{Offset: 2, Size: 1, Bit: 0, Bits: 2, Name: "flag", Type: "bits=2", Value: uint64(3)},
+		{Offset: 2, Size: 1, Bit: 2, Bits: 6, Name: "rest", Type: "bits=6", Value: uint64(0)},
+	}
+	var out bytes.Buffer
+	require.NoError(t, Annotate(&out, data, spans, Options{}))
+	assert.Equal(t, strings.Join([]string{
+		"00000000    10 37                                            id    u16     4151",
+		"00000002.0  c0                                               flag  bits=2  3",
+		"00000002.2  c0                                               rest  bits=6  0",
+		"00000003    00 00 00                                         (skipped, 3 bytes)",
+		"00000006    61 62 63 64 65 66 67 68 69 6a 6b 6c 6d 6e 6f 70  name  string  \"abcdefghijklmnopqrstuvwxyz\"",
+		"00000016    71 72 73 74 75 76 77 78 79 7a 00",
+		"00000021    de ad                                            (unconsumed, 2 bytes)",
+		"",
+	}, "\n"), out.String())
+
+	out.Reset()
+	require.NoError(t, Annotate(&out, data[:4], spans[1:2], Options{Color: true}))
+	assert.Contains(t, out.String(), colorUnconsumed+"00000002    c0 00")
+	assert.Contains(t, out.String(), "(unconsumed, 2 bytes)"+colorReset)
+}
+
+func TestAnnotate_PastEnd(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Annotate(&out, []byte{1}, []Span{{Offset: 0, Size: 4, Name: "v", Type: "u32"}}, Options{}))
+	assert.Equal(t, "00000000    01                                               v  u32\n", out.String())
+}
+
+func TestAnnotate_Invalid(t *testing.T) {
+	for _, span := range []Span{{Offset: -1, Size: 2}, {Offset: 0, Size: -3}} {
+		var out bytes.Buffer
+		assert.Error(t, Annotate(&out, []byte{1, 2}, []Span{span}, Options{}))
+		assert.Empty(t, out.String())
+	}
+
+	_, err := ReadSpans(strings.NewReader(`[{"offset": -1, "size": 1, "name": "v"}]`))
+	assert.EqualError(t, err, `hexdump: span "v" has offset -1 and size 1`)
+}
+
+func TestSpans_JSON(t *testing.T) {
+	spans := []Span{
+		{Offset: 0, Size: 8, Name: "big", Type: "u64", Value: uint64(1<<64 - 1)},
+		{Offset: 8, Size: 1, Bits: 3, Name: "bits", Type: "bits=3", Value: uint64(5)},
+	}
+	var out bytes.Buffer
+	require.NoError(t, WriteSpans(&out, spans))
+	assert.Equal(t, "[\n"+
+		`  {"offset":0,"size":8,"name":"big","type":"u64","value":18446744073709551615},`+"\n"+
+		`  {"offset":8,"size":1,"bits":3,"name":"bits","type":"bits=3","value":5}`+"\n]\n", out.String())
+
+	read, err := ReadSpans(&out)
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("18446744073709551615"), read[0].Value)
+	assert.Equal(t, 3, read[1].Bits)
+
+	_, err = ReadSpans(strings.NewReader("{"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+package bytepal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrHuffmanCode is returned when text can not be encoded or decoded with a Huffman table.
+var ErrHuffmanCode = errors.New("bytepal: invalid huffman code")
+
+// Huffman compresses text with the canonical Huffman code given by the bit length of each byte value.
+type Huffman struct {
+	codes   [256]uint32
+	lengths [256]uint8
+	// tree holds two children per node, positive values index a node and negative values are ^symbol.
+	tree [][2]int32
+}
+
+// NewHuffman builds the canonical code from a table of up to 256 bit lengths, as loaded from the cache.
+//	A length of zero leaves the byte value without a code.
+func NewHuffman(lengths []byte) (*Huffman, error) {
+	if len(lengths) > 256 {
+		return nil, fmt.Errorf("%w: %d bit lengths for 256 symbols", ErrHuffmanCode, len(lengths))
+	}
+	h := &Huffman{
+		tree: make([][2]int32, 1, 2*len(lengths)),
+	}
+	symbols := make([]int, 0, len(lengths))
+	for symbol, length := range lengths {
+		if length > 32 {
+			return nil, fmt.Errorf("%w: bit length %d for symbol %d", ErrHuffmanCode, length, symbol)
+		}
+		if length > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return lengths[symbols[i]] < lengths[symbols[j]]
+	})
+
+	code, previous := uint64(0), uint8(0)
+	for _, symbol := range symbols {
+		length := lengths[symbol]
+		code <<= length - previous
+		if code >= 1<<length {
+			return nil, fmt.Errorf("%w: bit lengths are over-subscribed", ErrHuffmanCode)
+		}
+		h.codes[symbol], h.lengths[symbol] = uint32(code), length
+		h.insert(uint32(code), length, symbol)
+		code, previous = code+1, length
+	}
+	return h, nil
+}
+
+// insert adds the path for code to the decoding tree.
+func (h *Huffman) insert(code uint32, length uint8, symbol int) {
+	node := 0
+	for bit := int(length) - 1; bit > 0; bit-- {
+		branch := code >> uint(bit) & 1
+		if h.tree[node][branch] == 0 {
+			h.tree = append(h.tree, [2]int32{})
+			h.tree[node][branch] = int32(len(h.tree) - 1)
+		}
+		node = int(h.tree[node][branch])
+	}
+	h.tree[node][code&1] = ^int32(symbol)
+}
+
+// Code returns the code and its bit length for a byte value, a length of zero means it has no code.
+func (h *Huffman) Code(symbol byte) (code uint32, length uint8) {
+	return h.codes[symbol], h.lengths[symbol]
+}
+
+// check fails text if one of its bytes has no code.
+func (h *Huffman) check(text string) error {
+	for i := 0; i < len(text); i++ {
+		if h.lengths[text[i]] == 0 {
+			return fmt.Errorf("%w: no code for byte %d", ErrHuffmanCode, text[i])
+		}
+	}
+	return nil
+}
+
+// encode writes the code of each byte of text through bits, text must have passed check.
+func (h *Huffman) encode(text string, bits func(uint, uint)) {
+	for i := 0; i < len(text); i++ {
+		bits(uint(h.lengths[text[i]]), uint(h.codes[text[i]]))
+	}
+}
+
+// decode reads size symbols through bits, stopping early once bits runs out.
+func (h *Huffman) decode(size int, bits func() (uint, bool)) (string, error) {
+	text := make([]byte, 0, size)
+	for node := int32(0); len(text) < size; {
+		bit, ok := bits()
+		if !ok {
+			return "", fmt.Errorf("%w: %d of %d symbols decoded", ErrHuffmanCode, len(text), size)
+		}
+		node = h.tree[node][bit]
+		switch {
+		case node < 0:
+			text = append(text, byte(^node))
+			node = 0
+		case node == 0:
+			return "", fmt.Errorf("%w: unused code after %d symbols", ErrHuffmanCode, len(text))
+		}
+	}
+	return string(text), nil
+}
+
+// Compress encodes text without a length prefix, the last byte is padded with zero bits.
+func (h *Huffman) Compress(text string) ([]byte, error) {
+	if err := h.check(text); err != nil {
+		return nil, err
+	}
+	out := NewExpandableWriterWithCap(len(text))
+	h.encode(text, out.BitAccess())
+	return out.Payload(), nil
+}
+
+// Decompress decodes size bytes of text from data.
+func (h *Huffman) Decompress(data []byte, size int) (string, error) {
+	position := 0
+	return h.decode(size, func() (uint, bool) {
+		if position >= len(data)*8 {
+			return 0, false
+		}
+		bit := uint(data[position>>3]>>(7-uint(position&7))) & 1
+		position++
+		return bit, true
+	})
+}
+
+// WriteText writes the length of text as a smart followed by its compressed bits. Nothing is written if
+//	text is too long or has a byte without a code.
+func (h *Huffman) WriteText(w Writer, text string) error {
+	if len(text) > 32767 {
+		return fmt.Errorf("%w: text of %d bytes", ErrRange, len(text))
+	}
+	if err := h.check(text); err != nil {
+		return err
+	}
+	w.WriteSmart(uint16(len(text)))
+	h.encode(text, w.BitAccess())
+	return nil
+}
+
+// ReadText reads text written by WriteText, an undecodable text is recorded as the error of the Reader.
+func (h *Huffman) ReadText(r *Reader) string {
+	size := int(r.ReadSmart())
+	bits := r.ReadBits()
+	text, err := h.decode(size, func() (uint, bool) {
+		bit := bits(1)
+		return bit, r.err == nil
+	})
+	if err != nil {
+		if r.err == nil {
+			r.fail(err)
+		}
+		return ""
+	}
+	return text
+}
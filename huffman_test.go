@@ -0,0 +1,96 @@
+package bytepal
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+// chatLengths gives the most common characters the shortest codes, every byte value is encodable.
+func chatLengths() []byte {
+	lengths := make([]byte, 256)
+	for i := range lengths {
+		lengths[i] = 10
+	}
+	for _, c := range " eta" {
+		lengths[c] = 3
+	}
+	for _, c := range "oinshrdlu" {
+		lengths[c] = 6
+	}
+	return lengths
+}
+
+func TestHuffman_CanonicalCodes(t *testing.T) {
+	// Example from RFC 1951 section 3.2.2
+	lengths := make([]byte, 'I')
+	for c, length := range map[byte]byte{'A': 3, 'B': 3, 'C': 3, 'D': 3, 'E': 3, 'F': 2, 'G': 4, 'H': 4} {
+		lengths[c] = length
+	}
+	h, err := NewHuffman(lengths)
+	require.NoError(t, err)
+
+	expected := map[byte]uint32{'F': 0x0, 'A': 0x2, 'B': 0x3, 'C': 0x4, 'D': 0x5, 'E': 0x6, 'G': 0xE, 'H': 0xF}
+	for c, code := range expected {
+		actual, length := h.Code(c)
+		assert.Equal(t, code, actual, string(c))
+		assert.Equal(t, lengths[c], length, string(c))
+	}
+
+	data, err := h.Compress("FAH")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x17, 0x80}, data)
+
+	text, err := h.Decompress(data, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "FAH", text)
+}
+
+func TestHuffman_OverSubscribed(t *testing.T) {
+	_, err := NewHuffman([]byte{1, 1, 1})
+	assert.True(t, errors.Is(err, ErrHuffmanCode))
+}
+
+func TestHuffman_Text(t *testing.T) {
+	h, err := NewHuffman(chatLengths())
+	require.NoError(t, err)
+
+	messages := []string{"", "hello there", "Zz9!~ the rain in spain", string([]byte{0, 255, 128})}
+	for _, out := range []Writer{NewFixedWriterWithPolicy(0, OverflowGrow), NewExpandableWriter()} {
+		for _, message := range messages {
+			require.NoError(t, h.WriteText(out, message))
+		}
+		out.WriteUInt8(0xAB)
+		require.NoError(t, out.Err())
+
+		in := NewCheckedReader(out.Payload())
+		for _, message := range messages {
+			assert.Equal(t, message, h.ReadText(in))
+		}
+		assert.Equal(t, uint8(0xAB), in.ReadUInt8())
+		assert.NoError(t, in.Err())
+	}
+}
+
+func TestHuffman_Errors(t *testing.T) {
+	lengths := make([]byte, 256)
+	lengths['a'] = 1
+	h, err := NewHuffman(lengths)
+	require.NoError(t, err)
+
+	out := NewExpandableWriter()
+	assert.True(t, errors.Is(h.WriteText(out, "ab"), ErrHuffmanCode))
+	assert.True(t, errors.Is(h.WriteText(out, strings.Repeat("a", 32768)), ErrRange))
+	assert.Equal(t, 0, out.Size(), "a rejected text must not write its length")
+
+	// The single code is 0, a set bit leads nowhere.
+	in := NewCheckedReader([]byte{2, 0x80})
+	assert.Equal(t, "", h.ReadText(in))
+	assert.True(t, errors.Is(in.Err(), ErrHuffmanCode))
+
+	in = NewCheckedReader([]byte{9, 0x00})
+	assert.Equal(t, "", h.ReadText(in))
+	assert.Error(t, in.Err())
+}
@@ -0,0 +1,149 @@
+package bytepal
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrUnreadByte is returned by UnreadByte at the start of the payload.
+var ErrUnreadByte = errors.New("bytepal: UnreadByte at the start of the payload")
+
+var (
+	_ io.Reader      = &Reader{}
+	_ io.ByteReader  = &Reader{}
+	_ io.ByteScanner = &Reader{}
+	_ io.Seeker      = &Reader{}
+	_ io.ReaderAt    = &Reader{}
+	_ io.WriterTo    = &Reader{}
+)
+
+// Read reads up to len(p) bytes into p, it returns io.EOF once no bytes are remaining.
+//	Unlike the other reads it never panics, and returns the sticky error of a checked Reader.
+func (b *Reader) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if b.currentIndex >= b.limit {
+		return 0, io.EOF
+	}
+	n := copy(p, b.bytes[b.currentIndex:b.limit])
+	b.currentIndex += n
+	return n, nil
+}
+
+// ReadByte reads a single byte, it returns io.EOF once no bytes are remaining.
+func (b *Reader) ReadByte() (byte, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.currentIndex >= b.limit {
+		return 0, io.EOF
+	}
+	b.currentIndex++
+	return b.bytes[b.currentIndex-1], nil
+}
+
+// UnreadByte moves the index pointer back a single byte.
+func (b *Reader) UnreadByte() error {
+	if b.err != nil {
+		return b.err
+	}
+	if b.currentIndex <= 0 {
+		return ErrUnreadByte
+	}
+	b.currentIndex--
+	return nil
+}
+
+// Seek sets the index pointer relative to the start, the current position or the limit of the Reader.
+//	Positions outside the payload return a ReadError and leave the index pointer in place.
+func (b *Reader) Seek(offset int64, whence int) (int64, error) {
+	var position int64
+	switch whence {
+	case io.SeekStart:
+		position = offset
+	case io.SeekCurrent:
+		position = int64(b.currentIndex) + offset
+	case io.SeekEnd:
+		position = int64(b.limit) + offset
+	default:
+		return 0, errors.New("bytepal: Seek with an invalid whence")
+	}
+	if position < 0 || position > int64(b.limit) {
+		return 0, &ReadError{Offset: int(position), Available: b.limit}
+	}
+	b.currentIndex = int(position)
+	return position, nil
+}
+
+// ReadAt reads len(p) bytes from offset off without moving the index pointer, reading stops at
+//	the limit of the Reader.
+func (b *Reader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, &ReadError{Offset: int(off), Size: len(p), Available: b.limit}
+	}
+	if off >= int64(b.limit) {
+		return 0, io.EOF
+	}
+	n := copy(p, b.bytes[off:b.limit])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteTo writes the remaining bytes to w and moves the index pointer past them.
+func (b *Reader) WriteTo(w io.Writer) (int64, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.currentIndex >= b.limit {
+		return 0, nil
+	}
+	remaining := b.limit - b.currentIndex
+	n, err := w.Write(b.bytes[b.currentIndex:b.limit])
+	b.currentIndex += n
+	if err == nil && n < remaining {
+		err = io.ErrShortWrite
+	}
+	return int64(n), err
+}
+
+// WriteByte writes a single byte, it returns the recorded error or io.ErrShortWrite when
+//	the overflow policy drops it.
+func (a *FixedWriter) WriteByte(c byte) error {
+	start := a.currentIndex
+	a.reserve(1)[0] = c
+	if a.currentIndex == start {
+		if a.err != nil {
+			return a.err
+		}
+		return io.ErrShortWrite
+	}
+	return nil
+}
+
+// WriteByte writes a single byte, it always succeeds.
+func (a *ExpandableWriter) WriteByte(c byte) error {
+	a.WriteUInt8(c)
+	return nil
+}
+
+// Read reads up to len(p) bytes into p, filling the buffer from the source when it is empty.
+func (s *StreamReader) Read(p []byte) (int, error) {
+	if len(p) == 0 || !s.fill(1) {
+		return 0, s.Err()
+	}
+	n := copy(p, s.reader.bytes[s.reader.currentIndex:])
+	s.reader.currentIndex += n
+	return n, nil
+}
+
+// ReadByte reads a single byte, it returns io.EOF at the end of the stream.
+func (s *StreamReader) ReadByte() (byte, error) {
+	v := s.ReadUInt8()
+	return v, s.Err()
+}
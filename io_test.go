@@ -0,0 +1,121 @@
+package bytepal
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/ioutil"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader_IOReader(t *testing.T) {
+	data := []byte(TestString)
+	require.NoError(t, iotest.TestReader(NewReader(data), data))
+}
+
+func TestReader_BinaryRead(t *testing.T) {
+	reader := NewReader([]byte{0x1, 0x2, 0x3, 0x4, 0x5, 0x6})
+	var v struct {
+		A uint16
+		B int32
+	}
+	require.NoError(t, binary.Read(reader, binary.LittleEndian, &v))
+	assert.Equal(t, uint16(0x201), v.A)
+	assert.Equal(t, int32(0x6050403), v.B)
+	assert.Equal(t, 0, reader.Remaining())
+}
+
+func TestReader_ByteScanner(t *testing.T) {
+	reader := NewReader([]byte{0x1, 0x2})
+	assert.Equal(t, ErrUnreadByte, reader.UnreadByte())
+	c, err := reader.ReadByte()
+	require.NoError(t, err)
+	assert.Equal(t, byte(1), c)
+	require.NoError(t, reader.UnreadByte())
+	assert.Equal(t, uint16(0x102), reader.ReadUInt16())
+	_, err = reader.ReadByte()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReader_Seek(t *testing.T) {
+	reader := NewReader([]byte{0x1, 0x2, 0x3, 0x4})
+	position, err := reader.Seek(-1, io.SeekEnd)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), position)
+	assert.Equal(t, uint8(4), reader.ReadUInt8())
+
+	position, err = reader.Seek(-3, io.SeekCurrent)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), position)
+
+	_, err = reader.Seek(5, io.SeekStart)
+	assert.IsType(t, &ReadError{}, err)
+	assert.Equal(t, uint8(2), reader.ReadUInt8())
+}
+
+func TestReader_Limited(t *testing.T) {
+	reader := NewReader([]byte{0x1, 0x2, 0x3, 0x4})
+	reader.PushLimit(2)
+	data, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, data)
+
+	p := make([]byte, 4)
+	n, err := reader.ReadAt(p, 1)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, io.EOF, err)
+	require.NoError(t, reader.PopLimit())
+	assert.Equal(t, 2, reader.Remaining())
+}
+
+func TestReader_WriteTo(t *testing.T) {
+	reader := NewReader([]byte{0x1, 0x2, 0x3})
+	reader.ReadUInt8()
+	var out bytes.Buffer
+	n, err := io.Copy(&out, reader)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, []byte{2, 3}, out.Bytes())
+	assert.Equal(t, 0, reader.Remaining())
+}
+
+func TestWriter_Gzip(t *testing.T) {
+	for _, out := range []Writer{NewExpandableWriter(), NewFixedWriterWithPolicy(0, OverflowGrow)} {
+		zw := gzip.NewWriter(out)
+		_, err := zw.Write([]byte(TestString))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+
+		zr, err := gzip.NewReader(NewReader(out.Payload()))
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, TestString, string(data))
+	}
+}
+
+func TestWriter_Bufio(t *testing.T) {
+	out := NewExpandableWriter()
+	bw := bufio.NewWriter(out)
+	require.NoError(t, bw.WriteByte(1))
+	_, err := bw.WriteString("ab")
+	require.NoError(t, err)
+	require.NoError(t, bw.Flush())
+	require.NoError(t, out.WriteByte(2))
+	assert.Equal(t, []byte{1, 'a', 'b', 2}, out.Payload())
+
+	fixed := NewFixedWriterWithPolicy(1, OverflowDrop)
+	assert.NoError(t, fixed.WriteByte(1))
+	assert.Equal(t, io.ErrShortWrite, fixed.WriteByte(2))
+}
+
+func TestStreamReader_IOReader(t *testing.T) {
+	data := []byte(TestString)
+	s := NewStreamReaderWithOrder(bytes.NewReader(data), 3, binary.BigEndian)
+	require.NoError(t, iotest.TestReader(struct{ io.Reader }{s}, data))
+}
@@ -0,0 +1,138 @@
+package bytepal
+
+// Cipher supplies the keystream used to encrypt packet opcodes.
+type Cipher interface {
+	NextInt() uint32
+}
+
+var _ Cipher = &ISAAC{}
+
+const isaacSize = 256
+
+// ISAAC is Bob Jenkins' ISAAC random number generator as used for opcode encryption.
+//	Each batch of results is handed out from the last to the first.
+type ISAAC struct {
+	results [isaacSize]uint32
+	memory  [isaacSize]uint32
+	a, b, c uint32
+	count   int
+}
+
+// NewISAAC creates a generator seeded with up to 256 keys, missing keys are zero.
+func NewISAAC(seed []uint32) *ISAAC {
+	r := &ISAAC{}
+	copy(r.results[:], seed)
+	r.init()
+	return r
+}
+
+// NextInt returns the next value of the keystream.
+func (r *ISAAC) NextInt() uint32 {
+	if r.count == 0 {
+		r.isaac()
+		r.count = isaacSize
+	}
+	r.count--
+	return r.results[r.count]
+}
+
+// isaac fills the results with the next batch of values.
+func (r *ISAAC) isaac() {
+	r.c++
+	r.b += r.c
+	for i, x := range r.memory {
+		switch i & 3 {
+		case 0:
+			r.a ^= r.a << 13
+		case 1:
+			r.a ^= r.a >> 6
+		case 2:
+			r.a ^= r.a << 2
+		case 3:
+			r.a ^= r.a >> 16
+		}
+		r.a += r.memory[(i+128)&(isaacSize-1)]
+		y := r.memory[(x>>2)&(isaacSize-1)] + r.a + r.b
+		r.memory[i] = y
+		r.b = r.memory[(y>>10)&(isaacSize-1)] + x
+		r.results[i] = r.b
+	}
+}
+
+// init scrambles the seed into the memory and generates the first batch of results.
+func (r *ISAAC) init() {
+	var s [8]uint32
+	for i := range s {
+		s[i] = 0x9e3779b9 // the golden ratio
+	}
+	for i := 0; i < 4; i++ {
+		isaacMix(&s)
+	}
+	for pass := 0; pass < 2; pass++ {
+		src := &r.results
+		if pass == 1 {
+			src = &r.memory
+		}
+		for i := 0; i < isaacSize; i += 8 {
+			for j := range s {
+				s[j] += src[i+j]
+			}
+			isaacMix(&s)
+			copy(r.memory[i:i+8], s[:])
+		}
+	}
+	r.isaac()
+	r.count = isaacSize
+}
+
+func isaacMix(s *[8]uint32) {
+	s[0] ^= s[1] << 11
+	s[3] += s[0]
+	s[1] += s[2]
+	s[1] ^= s[2] >> 2
+	s[4] += s[1]
+	s[2] += s[3]
+	s[2] ^= s[3] << 8
+	s[5] += s[2]
+	s[3] += s[4]
+	s[3] ^= s[4] >> 16
+	s[6] += s[3]
+	s[4] += s[5]
+	s[4] ^= s[5] << 10
+	s[7] += s[4]
+	s[5] += s[6]
+	s[5] ^= s[6] >> 4
+	s[0] += s[5]
+	s[6] += s[7]
+	s[6] ^= s[7] << 8
+	s[1] += s[6]
+	s[7] += s[0]
+	s[7] ^= s[0] >> 9
+	s[2] += s[7]
+	s[0] += s[1]
+}
+
+// ReadOpcode reads a byte and removes the next value of the cipher from it, a nil cipher reads a plain byte.
+func (b *Reader) ReadOpcode(cipher Cipher) uint8 {
+	op := b.ReadUInt8()
+	if cipher != nil {
+		op -= uint8(cipher.NextInt())
+	}
+	return op
+}
+
+// WriteOpcode writes op with the next value of the cipher added to it, a nil cipher writes a plain byte.
+func (a *FixedWriter) WriteOpcode(cipher Cipher, op uint8) {
+	if cipher != nil {
+		op += uint8(cipher.NextInt())
+	}
+	a.WriteUInt8(op)
+}
+
+// WriteOpcode writes op with the next value of the cipher added to it, a nil cipher writes a plain byte.
+func (a *ExpandableWriter) WriteOpcode(cipher Cipher, op uint8) {
+	if cipher != nil {
+		op += uint8(cipher.NextInt())
+	}
+	a.WriteUInt8(op)
+}
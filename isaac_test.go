@@ -0,0 +1,57 @@
+package bytepal
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+// TestISAAC_KnownAnswer checks the generator against randvect.txt from the reference implementation,
+//	which prints the two batches following the one generated during initialisation with a zero seed.
+func TestISAAC_KnownAnswer(t *testing.T) {
+	file, err := os.Open("testdata/isaac_randvect.txt")
+	require.NoError(t, err)
+	defer file.Close()
+
+	expected := make([]string, 0, 512)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		for i := 0; i+8 <= len(line); i += 8 {
+			expected = append(expected, line[i:i+8])
+		}
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, expected, 512)
+
+	r := NewISAAC(nil)
+	for i := 0; i < isaacSize; i++ {
+		r.NextInt()
+	}
+	for batch := 0; batch < 2; batch++ {
+		values := make([]string, isaacSize)
+		for i := isaacSize - 1; i >= 0; i-- {
+			values[i] = fmt.Sprintf("%.8x", r.NextInt())
+		}
+		assert.Equal(t, expected[batch*isaacSize:(batch+1)*isaacSize], values)
+	}
+}
+
+func TestWriter_WriteOpcode(t *testing.T) {
+	seed := []uint32{1, 2, 3, 4}
+	for _, out := range []Writer{NewFixedWriter(3), NewExpandableWriter()} {
+		cipher := NewISAAC(seed)
+		out.WriteOpcode(cipher, 10)
+		out.WriteOpcode(cipher, 255)
+		out.WriteOpcode(nil, 7)
+
+		in := NewReader(out.Payload())
+		cipher = NewISAAC(seed)
+		assert.Equal(t, uint8(10), in.ReadOpcode(cipher))
+		assert.Equal(t, uint8(255), in.ReadOpcode(cipher))
+		assert.Equal(t, uint8(7), in.ReadOpcode(nil))
+	}
+}
@@ -0,0 +1,103 @@
+package bytepal
+
+import "fmt"
+
+// LengthVarint reserves a LEB128 varint length, it is re-encoded and the body moved if it needs more than a byte.
+const LengthVarint = -1
+
+// LengthMark is a placeholder for a length reserved by ReserveLength.
+type LengthMark struct {
+	// start is the offset of the first byte after the placeholder.
+	start int
+	size  int
+}
+
+// reserveLength writes a zeroed placeholder of size bytes through w.
+func (a *bitWriter) reserveLength(w Writer, size int) LengthMark {
+	placeholder := size
+	switch size {
+	case 1, 2, 4:
+	case LengthVarint:
+		placeholder = 1
+	default:
+		a.fail(fmt.Errorf("%w: length placeholder of %d bytes", ErrRange, size))
+		return LengthMark{}
+	}
+	var zero [4]byte
+	if _, err := w.Write(zero[:placeholder]); err != nil {
+		// The overflow policy dropped the placeholder and recorded the error if it records one,
+		//	the zero mark leaves nothing to fill in.
+		return LengthMark{}
+	}
+	return LengthMark{
+		start: a.currentIndex,
+		size:  size,
+	}
+}
+
+// finishLength fills in the placeholder of mark with the number of bytes written after it.
+//	The zero mark of a failed reserveLength is ignored.
+func (a *bitWriter) finishLength(w Writer, mark LengthMark) {
+	if a.err != nil || mark.size == 0 {
+		return
+	}
+	length := a.currentIndex - mark.start
+	switch mark.size {
+	case 1:
+		if length > 0xFF {
+			break
+		}
+		a.bytes[mark.start-1] = byte(length)
+		return
+	case 2:
+		if length > 0xFFFF {
+			break
+		}
+		a.putUint16(a.bytes[mark.start-2:], uint16(length))
+		return
+	case 4:
+		if uint64(length) > 0xFFFFFFFF {
+			break
+		}
+		a.putUint32(a.bytes[mark.start-4:], uint32(length))
+		return
+	case LengthVarint:
+		size := uvarintLen(uint64(length))
+		if size > 1 {
+			// Grow through w so a FixedWriter applies its overflow policy, then move the body along.
+			var zero [MaxVarintLen64]byte
+			if _, err := w.Write(zero[:size-1]); err != nil {
+				// The growth was dropped, the placeholder is left unpatched like a dropped write.
+				return
+			}
+			copy(a.bytes[mark.start+size-1:], a.bytes[mark.start:mark.start+length])
+		}
+		putUVarint(a.bytes[mark.start-1:mark.start-1+size], uint64(length))
+		return
+	}
+	a.fail(fmt.Errorf("%w: length %d in a %d byte placeholder", ErrRange, length, mark.size))
+}
+
+// ReserveLength writes a placeholder of 1, 2 or 4 bytes, or LengthVarint, to be filled in by FinishLength.
+//	Placeholders may be nested, the innermost must be finished first.
+func (a *FixedWriter) ReserveLength(size int) LengthMark {
+	return a.reserveLength(a, size)
+}
+
+// FinishLength fills in the placeholder of mark with the number of bytes written since it was reserved.
+//	2 and 4 byte lengths follow the byte order of the Writer.
+func (a *FixedWriter) FinishLength(mark LengthMark) {
+	a.finishLength(a, mark)
+}
+
+// ReserveLength writes a placeholder of 1, 2 or 4 bytes, or LengthVarint, to be filled in by FinishLength.
+//	Placeholders may be nested, the innermost must be finished first.
+func (a *ExpandableWriter) ReserveLength(size int) LengthMark {
+	return a.reserveLength(a, size)
+}
+
+// FinishLength fills in the placeholder of mark with the number of bytes written since it was reserved.
+//	2 and 4 byte lengths follow the byte order of the Writer.
+func (a *ExpandableWriter) FinishLength(mark LengthMark) {
+	a.finishLength(a, mark)
+}
@@ -0,0 +1,95 @@
+package bytepal
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testLengths(t *testing.T, out Writer) {
+	outer := out.ReserveLength(2)
+	out.WriteUInt8(0xAA)
+	inner := out.ReserveLength(1)
+	out.WriteString("hi", 0)
+	out.FinishLength(inner)
+	varint := out.ReserveLength(LengthVarint)
+	out.Write(make([]byte, 200))
+	out.FinishLength(varint)
+	word := out.ReserveLength(4)
+	bits := out.BitAccess()
+	bits(3, 5)
+	bits(7, 1)
+	out.FinishLength(word)
+	out.FinishLength(outer)
+	require.NoError(t, out.Err())
+
+	in := NewCheckedReader(out.Payload())
+	assert.Equal(t, uint16(1+1+3+2+200+4+2), in.ReadUInt16())
+	assert.Equal(t, uint8(0xAA), in.ReadUInt8())
+	assert.Equal(t, uint8(3), in.ReadUInt8())
+	assert.Equal(t, "hi", in.ReadString(0))
+	assert.Equal(t, uint64(200), in.ReadUVarint())
+	assert.Equal(t, make([]byte, 200), in.ReadSlice(200))
+	assert.Equal(t, uint32(2), in.ReadUInt32())
+	assert.Equal(t, 2, in.Remaining())
+	assert.NoError(t, in.Err())
+}
+
+func TestFixedWriter_Lengths(t *testing.T) {
+	testLengths(t, NewFixedWriter(215))
+}
+func TestExpandableWriter_Lengths(t *testing.T) {
+	testLengths(t, NewExpandableWriter())
+}
+
+func TestWriter_LengthOverflow(t *testing.T) {
+	out := NewExpandableWriter()
+	mark := out.ReserveLength(1)
+	out.Write(make([]byte, 256))
+	out.FinishLength(mark)
+	assert.True(t, errors.Is(out.Err(), ErrRange))
+
+	out = NewExpandableWriter()
+	out.ReserveLength(3)
+	assert.True(t, errors.Is(out.Err(), ErrRange))
+
+	// Re-encoding the varint does not fit, the overflow policy applies.
+	out = NewFixedWriterWithPolicy(129, OverflowError)
+	mark = out.ReserveLength(LengthVarint)
+	out.Write(make([]byte, 128))
+	out.FinishLength(mark)
+	var writeErr *WriteError
+	assert.True(t, errors.As(out.Err(), &writeErr))
+}
+
+func TestFixedWriter_LengthPlaceholderOverflow(t *testing.T) {
+	// A dropped placeholder must not let FinishLength overwrite the bytes before it.
+	out := NewFixedWriterWithPolicy(2, OverflowDrop)
+	out.WriteUInt16(0xAABB)
+	mark := out.ReserveLength(2)
+	out.FinishLength(mark)
+	assert.NoError(t, out.Err(), "OverflowDrop drops silently")
+	assert.Equal(t, []byte{0xAA, 0xBB}, out.Payload())
+
+	out = NewFixedWriterWithPolicy(0, OverflowDrop)
+	assert.NotPanics(t, func() { out.FinishLength(out.ReserveLength(1)) })
+	assert.NoError(t, out.Err())
+
+	out = NewFixedWriterWithPolicy(3, OverflowError)
+	out.WriteUInt16(0xAABB)
+	mark = out.ReserveLength(2)
+	out.FinishLength(mark)
+	assert.Equal(t, &WriteError{Offset: 2, Size: 2, Available: 1}, out.Err())
+	assert.Equal(t, []byte{0xAA, 0xBB, 0}, out.Payload())
+
+	// A dropped varint growth leaves the body where it is and the placeholder unpatched.
+	out = NewFixedWriterWithPolicy(130, OverflowDrop)
+	mark = out.ReserveLength(LengthVarint)
+	body := make([]byte, 129)
+	body[0], body[128] = 1, 2
+	out.Write(body)
+	out.FinishLength(mark)
+	assert.NoError(t, out.Err())
+	assert.Equal(t, append([]byte{0}, body...), out.Payload())
+}
@@ -0,0 +1,53 @@
+package bytepal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoLimit is returned by PopLimit when no limit was pushed.
+var ErrNoLimit = errors.New("bytepal: no limit to pop")
+
+// LimitError is returned by PopLimit when the limited region was not read to its end.
+type LimitError struct {
+	// Offset is the end of the limited region.
+	Offset int
+	// Leftover is the number of bytes that were not read.
+	Leftover int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("bytepal: %d bytes left unread before offset %d", e.Leftover, e.Offset)
+}
+
+// PushLimit restricts reads to the next n bytes until PopLimit is called, limits may be nested.
+//	A limit past the current one is a ReadError.
+func (b *Reader) PushLimit(n int) {
+	if n < 0 || n > b.Remaining() {
+		b.fail(&ReadError{Offset: b.currentIndex, Size: n, Available: b.Remaining()})
+		n = 0
+	}
+	b.limits = append(b.limits, b.limit)
+	b.limit = b.currentIndex + n
+}
+
+// PopLimit removes the last limit pushed. If the limited bytes were not all read they are skipped
+//	and a LimitError reports how many were left.
+func (b *Reader) PopLimit() error {
+	if len(b.limits) == 0 {
+		return ErrNoLimit
+	}
+	end := b.limit
+	b.limit = b.limits[len(b.limits)-1]
+	b.limits = b.limits[:len(b.limits)-1]
+	if leftover := end - b.currentIndex; leftover != 0 {
+		b.currentIndex = end
+		return &LimitError{Offset: end, Leftover: leftover}
+	}
+	return nil
+}
+
+// Limit returns a Reader over the next n bytes and moves past them.
+func (b *Reader) Limit(n int) *Reader {
+	return b.derive(b.ReadSlice(n))
+}
@@ -0,0 +1,67 @@
+package bytepal
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+func TestReader_PushLimit(t *testing.T) {
+	in := NewCheckedReader([]byte{1, 2, 3, 'h', 'i', 0, 4, 0xF0, 5})
+	in.PushLimit(7)
+	assert.Equal(t, uint8(1), in.ReadUInt8())
+	in.PushLimit(2)
+	assert.Equal(t, 2, in.Remaining())
+	assert.Equal(t, uint16(0x203), in.ReadUInt16())
+	assert.NoError(t, in.PopLimit())
+	assert.Equal(t, "hi", in.ReadString(0))
+	assert.Equal(t, 1, in.Remaining())
+	assert.Equal(t, uint8(4), in.ReadUInt8())
+	assert.NoError(t, in.PopLimit())
+
+	bits := in.ReadBits()
+	assert.Equal(t, uint(0xF), bits(4))
+	assert.Equal(t, uint8(5), in.ReadUInt8())
+	assert.NoError(t, in.Err())
+	assert.Equal(t, ErrNoLimit, in.PopLimit())
+}
+
+func TestReader_LimitBounds(t *testing.T) {
+	reads := map[string]func(r *Reader){
+		"ReadUInt32":  func(r *Reader) { r.ReadUInt32() },
+		"ReadString":  func(r *Reader) { r.ReadString(0) },
+		"ReadBits":    func(r *Reader) { r.ReadBits()(17) },
+		"ReadUVarint": func(r *Reader) { r.ReadUVarint() },
+		"PushLimit":   func(r *Reader) { r.PushLimit(3) },
+	}
+	for name, read := range reads {
+		in := NewCheckedReader([]byte{0x80, 0x80, 0, 0, 0})
+		in.PushLimit(2)
+		read(in)
+		assert.True(t, errors.Is(in.Err(), io.ErrUnexpectedEOF), name)
+	}
+}
+
+func TestReader_PopLimitLeftover(t *testing.T) {
+	in := NewCheckedReader([]byte{1, 2, 3, 4})
+	in.PushLimit(3)
+	in.ReadUInt8()
+	err := in.PopLimit()
+	var limitErr *LimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, 3, limitErr.Offset)
+	assert.Equal(t, 2, limitErr.Leftover)
+	assert.Equal(t, uint8(4), in.ReadUInt8())
+}
+
+func TestReader_Limit(t *testing.T) {
+	in := NewCheckedReader([]byte{1, 2, 3})
+	sub := in.Limit(2)
+	assert.Equal(t, uint8(3), in.ReadUInt8())
+	assert.Equal(t, uint16(0x102), sub.ReadUInt16())
+	sub.ReadUInt8()
+	assert.Error(t, sub.Err())
+	assert.NoError(t, in.Err())
+}
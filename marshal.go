@@ -0,0 +1,632 @@
+package bytepal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// integer reads and writes one of the integer encodings of a Tag, signed values are sign extended.
+type integer struct {
+	read  func(r *Reader, t Transform) uint64
+	write func(w Writer, v uint64, t Transform)
+	// size is the number of bits the value must fit in, signed values are checked as two's complement.
+	size   uint
+	signed bool
+}
+
+var integers = map[string]integer{
+	"u8": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadUInt8T(t)) },
+		func(w Writer, v uint64, t Transform) { w.WriteUInt8T(uint8(v), t) }, 8, false},
+	"i8": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadInt8T(t)) },
+		func(w Writer, v uint64, t Transform) { w.WriteUInt8T(uint8(v), t) }, 8, true},
+	"u16": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadUInt16T(t)) },
+		func(w Writer, v uint64, t Transform) { w.WriteInt16T(int16(v), t) }, 16, false},
+	"i16": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadInt16T(t)) },
+		func(w Writer, v uint64, t Transform) { w.WriteInt16T(int16(v), t) }, 16, true},
+	"u16,le": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadLEUInt16T(t)) },
+		func(w Writer, v uint64, t Transform) { w.WriteLEInt16T(int16(v), t) }, 16, false},
+	"i16,le": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadLEInt16T(t)) },
+		func(w Writer, v uint64, t Transform) { w.WriteLEInt16T(int16(v), t) }, 16, true},
+	"u24": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadUMedium()) },
+		func(w Writer, v uint64, t Transform) { w.WriteMedium(int32(v)) }, 24, false},
+	"i24": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadMedium()) },
+		func(w Writer, v uint64, t Transform) { w.WriteMedium(int32(v)) }, 24, true},
+	"u24,le": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadLEUMedium()) },
+		func(w Writer, v uint64, t Transform) { w.WriteLEMedium(int32(v)) }, 24, false},
+	"i24,le": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadLEMedium()) },
+		func(w Writer, v uint64, t Transform) { w.WriteLEMedium(int32(v)) }, 24, true},
+	"u32": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadUInt32T(t)) },
+		func(w Writer, v uint64, t Transform) { w.WriteInt32T(int32(v), t) }, 32, false},
+	"i32": {
+		func(r *Reader, t Transform) uint64 { return uint64(int32(r.ReadUInt32T(t))) },
+		func(w Writer, v uint64, t Transform) { w.WriteInt32T(int32(v), t) }, 32, true},
+	"u32,le": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadLEUInt32T(t)) },
+		func(w Writer, v uint64, t Transform) { w.WriteLEInt32T(int32(v), t) }, 32, false},
+	"i32,le": {
+		func(r *Reader, t Transform) uint64 { return uint64(int32(r.ReadLEUInt32T(t))) },
+		func(w Writer, v uint64, t Transform) { w.WriteLEInt32T(int32(v), t) }, 32, true},
+	"u32,me": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadMEUInt32()) },
+		func(w Writer, v uint64, t Transform) { w.WriteMEUInt32(uint32(v)) }, 32, false},
+	"i32,me": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadMEInt32()) },
+		func(w Writer, v uint64, t Transform) { w.WriteMEInt32(int32(v)) }, 32, true},
+	"u32,ime": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadIMEUInt32()) },
+		func(w Writer, v uint64, t Transform) { w.WriteIMEUInt32(uint32(v)) }, 32, false},
+	"i32,ime": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadIMEInt32()) },
+		func(w Writer, v uint64, t Transform) { w.WriteIMEInt32(int32(v)) }, 32, true},
+	"u64": {
+		func(r *Reader, t Transform) uint64 { return r.ReadUInt64() },
+		func(w Writer, v uint64, t Transform) { w.WriteUInt64(v) }, 64, false},
+	"i64": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadInt64()) },
+		func(w Writer, v uint64, t Transform) { w.WriteInt64(int64(v)) }, 64, true},
+	"u64,le": {
+		func(r *Reader, t Transform) uint64 { return r.ReadLEUInt64() },
+		func(w Writer, v uint64, t Transform) { w.WriteLEUInt64(v) }, 64, false},
+	"i64,le": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadLEInt64()) },
+		func(w Writer, v uint64, t Transform) { w.WriteLEInt64(int64(v)) }, 64, true},
+	"smart": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadSmart()) },
+		func(w Writer, v uint64, t Transform) { w.WriteSmart(uint16(v)) }, 16, false},
+	"ssmart": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadSignedSmart()) },
+		func(w Writer, v uint64, t Transform) { w.WriteSignedSmart(int16(v)) }, 16, true},
+	"bigsmart": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadBigSmartValue()) },
+		func(w Writer, v uint64, t Transform) { w.WriteBigSmart(uint32(v)) }, 32, false},
+	"nbigsmart": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadNullableBigSmart()) },
+		func(w Writer, v uint64, t Transform) { w.WriteNullableBigSmart(int32(v)) }, 32, true},
+	"incrsmart": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadIncrSmart()) },
+		func(w Writer, v uint64, t Transform) { w.WriteIncrSmart(int(v)) }, 32, false},
+	"uvarint": {
+		func(r *Reader, t Transform) uint64 { return r.ReadUVarint() },
+		func(w Writer, v uint64, t Transform) { w.WriteUVarint(v) }, 64, false},
+	"varint": {
+		func(r *Reader, t Transform) uint64 { return uint64(r.ReadVarint()) },
+		func(w Writer, v uint64, t Transform) { w.WriteVarint(int64(v)) }, 64, true},
+	"quic": {
+		func(r *Reader, t Transform) uint64 { return r.ReadQUICVarint() },
+		func(w Writer, v uint64, t Transform) { w.WriteQUICVarint(v) }, 62, false},
+	"sqlite": {
+		func(r *Reader, t Transform) uint64 { return r.ReadSQLiteVarint() },
+		func(w Writer, v uint64, t Transform) { w.WriteSQLiteVarint(v) }, 64, false},
+	"compact": {
+		func(r *Reader, t Transform) uint64 { return r.ReadCompactSize() },
+		func(w Writer, v uint64, t Transform) { w.WriteCompactSize(v) }, 64, false},
+}
+
+// Integer reports whether t is one of the integer encodings supported by ReadInteger and WriteInteger.
+func (t Tag) Integer() bool {
+	_, ok := integers[t.Key()]
+	return ok
+}
+
+// Signed reports whether t is a signed integer encoding.
+func (t Tag) Signed() bool {
+	return integers[t.Key()].signed
+}
+
+// ReadInteger reads an integer encoded as t, signed encodings are sign extended. t must be an Integer.
+func (t Tag) ReadInteger(r *Reader) uint64 {
+	return integers[t.Key()].read(r, t.Transform)
+}
+
+// WriteInteger writes v encoded as t, a value that does not fit returns an error wrapping ErrRange.
+//	Signed values are passed sign extended. t must be an Integer.
+func (t Tag) WriteInteger(w Writer, v uint64) error {
+	encoding := integers[t.Key()]
+	if !fits(v, encoding.size, encoding.signed) {
+		if encoding.signed {
+			return fmt.Errorf("%w: %d does not fit %s", ErrRange, int64(v), t.Key())
+		}
+		return fmt.Errorf("%w: %d does not fit %s", ErrRange, v, t.Key())
+	}
+	encoding.write(w, v, t.Transform)
+	return nil
+}
+
+// fits reports whether v can be stored in size bits.
+func fits(v uint64, size uint, signed bool) bool {
+	if size >= 64 {
+		return true
+	}
+	if signed {
+		high := int64(v) >> (size - 1)
+		return high == 0 || high == -1
+	}
+	return v>>size == 0
+}
+
+// ErrDepth is recorded when decoded structs nest deeper than maxDepth, which only a recursive type
+//	such as a struct holding a slice of itself can do.
+var ErrDepth = errors.New("bytepal: structs nested too deeply")
+
+// maxDepth bounds the nesting of decoded structs, so untrusted input can not exhaust the stack.
+const maxDepth = 256
+
+// decoder holds the state of a single Unmarshal.
+type decoder struct {
+	r *Reader
+	// bits is shared by consecutive bit fields.
+	bits func(uint) uint
+	// depth counts the nested structs being decoded.
+	depth int
+}
+
+// encoder holds the state of a single Marshal.
+type encoder struct {
+	w    Writer
+	bits func(uint, uint)
+	err  error
+}
+
+// codec decodes into and encodes from a value of one Go type.
+type codec struct {
+	decode func(d *decoder, v reflect.Value)
+	encode func(e *encoder, v reflect.Value)
+}
+
+// field is a tagged field of a struct.
+type field struct {
+	index int
+	name  string
+	codec codec
+}
+
+// structPlan is the cached list of fields of a struct type.
+type structPlan struct {
+	fields []field
+}
+
+var plans sync.Map
+
+// Unmarshal decodes the next bytes of r into the struct pointed to by v following its bytepal tags.
+//	A short read is returned as a ReadError and any other read failure as its error, even if r was not
+//	created with NewCheckedReader. Values that do not fit their field return an error wrapping ErrRange.
+func Unmarshal(r *Reader, v interface{}) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bytepal: Unmarshal needs a non-nil pointer to a struct, got %T", v)
+	}
+	plan, err := planOf(value.Elem().Type())
+	if err != nil {
+		return err
+	}
+	if !r.Checked() {
+		// Decoding in checked mode returns the failures of an unchecked Reader instead of panicking.
+		r.SetChecked(true)
+		defer r.SetChecked(false)
+	}
+	plan.decode(&decoder{r: r}, value.Elem())
+	return r.Err()
+}
+
+// Marshal writes the struct, or pointer to a struct, v to w following its bytepal tags.
+//	Values that do not fit their encoding return an error wrapping ErrRange, a FixedWriter that overflows
+//	returns its WriteError.
+func Marshal(v interface{}, w Writer) (err error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("bytepal: Marshal needs a struct, got %T", v)
+	}
+	plan, err := planOf(value.Type())
+	if err != nil {
+		return err
+	}
+	if !value.CanAddr() {
+		// Byte arrays are written by slicing them, which needs an addressable copy.
+		addressable := reflect.New(value.Type()).Elem()
+		addressable.Set(value)
+		value = addressable
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			writeErr, ok := rec.(*WriteError)
+			if !ok {
+				panic(rec)
+			}
+			err = writeErr
+		}
+	}()
+	e := &encoder{w: w}
+	plan.encode(e, value)
+	if e.err != nil {
+		return e.err
+	}
+	return w.Err()
+}
+
+func (p *structPlan) decode(d *decoder, v reflect.Value) {
+	for _, f := range p.fields {
+		f.codec.decode(d, v.Field(f.index))
+	}
+}
+
+func (p *structPlan) encode(e *encoder, v reflect.Value) {
+	for _, f := range p.fields {
+		if e.err != nil {
+			return
+		}
+		f.codec.encode(e, v.Field(f.index))
+		if e.err != nil {
+			e.err = fmt.Errorf("%w in field %s", e.err, f.name)
+		}
+	}
+}
+
+// planOf returns the cached plan of the struct type t, building it on first use.
+func planOf(t reflect.Type) (*structPlan, error) {
+	if plan, ok := plans.Load(t); ok {
+		return plan.(*structPlan), nil
+	}
+	plan, err := buildPlan(t, map[reflect.Type]*structPlan{})
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := plans.LoadOrStore(t, plan)
+	return actual.(*structPlan), nil
+}
+
+// buildPlan builds the plan of t, building holds the plans of the enclosing structs so types may recurse.
+func buildPlan(t reflect.Type, building map[reflect.Type]*structPlan) (*structPlan, error) {
+	if plan, ok := plans.Load(t); ok {
+		return plan.(*structPlan), nil
+	}
+	if plan, ok := building[t]; ok {
+		return plan, nil
+	}
+	plan := &structPlan{}
+	building[t] = plan
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag, err := ParseTag(sf.Tag.Get("bytepal"))
+		if err == nil && tag.Skip {
+			continue
+		}
+		var c codec
+		if err == nil {
+			c, err = buildCodec(sf.Type, tag, building)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bytepal: %s.%s: %w", t, sf.Name, err)
+		}
+		plan.fields = append(plan.fields, field{index: i, name: sf.Name, codec: c})
+	}
+	return plan, nil
+}
+
+// buildCodec builds the codec of a value of type t encoded as tag.
+func buildCodec(t reflect.Type, tag Tag, building map[reflect.Type]*structPlan) (codec, error) {
+	switch t.Kind() {
+	case reflect.Struct:
+		if tag.Type != "" || tag.Count != "" {
+			return codec{}, fmt.Errorf("%w: struct %s can not be tagged %q", ErrTag, t, tag.Key())
+		}
+		plan, err := buildPlan(t, building)
+		if err != nil {
+			return codec{}, err
+		}
+		// Nested structs start and end on a byte boundary.
+		return codec{
+			decode: func(d *decoder, v reflect.Value) {
+				if d.depth == maxDepth {
+					d.r.fail(fmt.Errorf("%w: %s below %d structs", ErrDepth, t, maxDepth))
+					return
+				}
+				d.bits = nil
+				d.depth++
+				plan.decode(d, v)
+				d.depth--
+				d.bits = nil
+			},
+			encode: func(e *encoder, v reflect.Value) {
+				e.bits = nil
+				plan.encode(e, v)
+				e.bits = nil
+			},
+		}, nil
+	case reflect.Array:
+		if tag.Count != "" {
+			return codec{}, fmt.Errorf("%w: array %s can not have a count", ErrTag, t)
+		}
+		return arrayCodec(t, tag, building)
+	case reflect.Slice:
+		if tag.Count == "" {
+			return codec{}, fmt.Errorf("%w: slice %s needs a count", ErrTag, t)
+		}
+		return sliceCodec(t, tag, building)
+	}
+	if tag.Count != "" {
+		return codec{}, fmt.Errorf("%w: %s can not have a count", ErrTag, t)
+	}
+	return leafCodec(t, tag)
+}
+
+// bytesTag reports whether elements of type t tagged with tag can be copied as raw bytes.
+func bytesTag(t reflect.Type, tag Tag) bool {
+	return t.Kind() == reflect.Uint8 && (tag.Type == "" || tag.Type == "u8") && tag.Transform == TransformNone
+}
+
+func arrayCodec(t reflect.Type, tag Tag, building map[reflect.Type]*structPlan) (codec, error) {
+	if bytesTag(t.Elem(), tag) {
+		return codec{
+			decode: func(d *decoder, v reflect.Value) {
+				d.bits = nil
+				d.r.ReadBytes(v.Slice(0, v.Len()).Bytes())
+			},
+			encode: func(e *encoder, v reflect.Value) {
+				e.bits = nil
+				e.w.Write(v.Slice(0, v.Len()).Bytes())
+			},
+		}, nil
+	}
+	elem, err := buildCodec(t.Elem(), tag, building)
+	if err != nil {
+		return codec{}, err
+	}
+	return codec{
+		decode: func(d *decoder, v reflect.Value) {
+			for i := 0; i < v.Len(); i++ {
+				elem.decode(d, v.Index(i))
+			}
+		},
+		encode: func(e *encoder, v reflect.Value) {
+			for i := 0; i < v.Len() && e.err == nil; i++ {
+				elem.encode(e, v.Index(i))
+			}
+		},
+	}, nil
+}
+
+func sliceCodec(t reflect.Type, tag Tag, building map[reflect.Type]*structPlan) (codec, error) {
+	count := integers[tag.Count]
+	decodeCount := func(d *decoder) int {
+		d.bits = nil
+		return d.r.Count(count.read(d.r, TransformNone))
+	}
+	encodeCount := func(e *encoder, n int) {
+		e.bits = nil
+		if !fits(uint64(n), count.size, false) {
+			e.err = fmt.Errorf("%w: count %d does not fit %s", ErrRange, n, tag.Count)
+			return
+		}
+		count.write(e.w, uint64(n), TransformNone)
+	}
+
+	elemTag := tag
+	elemTag.Count = ""
+	if bytesTag(t.Elem(), elemTag) {
+		return codec{
+			decode: func(d *decoder, v reflect.Value) {
+				// Only the bytes actually read are allocated, whatever the count claims.
+				v.SetBytes(append([]byte{}, d.r.ReadSlice(decodeCount(d))...))
+			},
+			encode: func(e *encoder, v reflect.Value) {
+				if encodeCount(e, v.Len()); e.err == nil {
+					e.w.Write(v.Bytes())
+				}
+			},
+		}, nil
+	}
+	elem, err := buildCodec(t.Elem(), elemTag, building)
+	if err != nil {
+		return codec{}, err
+	}
+	return codec{
+		decode: func(d *decoder, v reflect.Value) {
+			// The slice grows as elements decode, so a count the input can not back allocates nothing
+			//	beyond the elements read before the failure, which are kept.
+			n := decodeCount(d)
+			slice := reflect.MakeSlice(t, 0, 0)
+			zero := reflect.Zero(t.Elem())
+			for i := 0; i < n; i++ {
+				slice = reflect.Append(slice, zero)
+				if elem.decode(d, slice.Index(i)); d.r.Err() != nil {
+					slice = slice.Slice(0, i)
+					break
+				}
+			}
+			v.Set(slice)
+		},
+		encode: func(e *encoder, v reflect.Value) {
+			encodeCount(e, v.Len())
+			for i := 0; i < v.Len() && e.err == nil; i++ {
+				elem.encode(e, v.Index(i))
+			}
+		},
+	}, nil
+}
+
+// inferTag returns the encoding of an untagged field of kind k.
+var inferTag = map[reflect.Kind]string{
+	reflect.Bool:    "u8",
+	reflect.Uint8:   "u8",
+	reflect.Int8:    "i8",
+	reflect.Uint16:  "u16",
+	reflect.Int16:   "i16",
+	reflect.Uint32:  "u32",
+	reflect.Int32:   "i32",
+	reflect.Uint64:  "u64",
+	reflect.Int64:   "i64",
+	reflect.Uint:    "u64",
+	reflect.Int:     "i64",
+	reflect.Float32: "f32",
+	reflect.Float64: "f64",
+	reflect.String:  "string",
+}
+
+func leafCodec(t reflect.Type, tag Tag) (codec, error) {
+	kind := t.Kind()
+	if tag.Type == "" {
+		tag.Type = inferTag[kind]
+	}
+	integerKind := kind == reflect.Bool || kind >= reflect.Int && kind <= reflect.Uint64
+	mismatch := fmt.Errorf("%w: %s can not be encoded as %q", ErrTag, t, tag.Key())
+	switch tag.Type {
+	case "":
+		return codec{}, fmt.Errorf("%w: %s has no encoding", ErrTag, t)
+	case "string":
+		if kind != reflect.String {
+			return codec{}, mismatch
+		}
+		return codec{
+			decode: func(d *decoder, v reflect.Value) {
+				d.bits = nil
+				v.SetString(d.r.ReadString(tag.Delim))
+			},
+			encode: func(e *encoder, v reflect.Value) {
+				e.bits = nil
+				e.w.WriteString(v.String(), tag.Delim)
+			},
+		}, nil
+	case "f32", "f64":
+		if kind != reflect.Float32 && kind != reflect.Float64 {
+			return codec{}, mismatch
+		}
+		return floatCodec(tag), nil
+	case "bits":
+		if !integerKind {
+			return codec{}, mismatch
+		}
+		return codec{
+			decode: func(d *decoder, v reflect.Value) {
+				if d.bits == nil {
+					d.bits = d.r.ReadBits()
+				}
+				d.setInteger(v, uint64(d.bits(tag.Bits)), false)
+			},
+			encode: func(e *encoder, v reflect.Value) {
+				value := getInteger(v)
+				if !fits(value, tag.Bits, false) {
+					e.err = fmt.Errorf("%w: %d does not fit %d bits", ErrRange, value, tag.Bits)
+					return
+				}
+				if e.bits == nil {
+					e.bits = e.w.BitAccess()
+				}
+				e.bits(tag.Bits, uint(value))
+			},
+		}, nil
+	}
+	encoding, ok := integers[tag.Key()]
+	if !ok || !integerKind {
+		return codec{}, mismatch
+	}
+	key := tag.Key()
+	return codec{
+		decode: func(d *decoder, v reflect.Value) {
+			d.bits = nil
+			d.setInteger(v, encoding.read(d.r, tag.Transform), encoding.signed)
+		},
+		encode: func(e *encoder, v reflect.Value) {
+			e.bits = nil
+			value := getInteger(v)
+			if isSigned(v.Kind()) != encoding.signed && int64(value) < 0 || !fits(value, encoding.size, encoding.signed) {
+				e.err = fmt.Errorf("%w: %d does not fit %s", ErrRange, int64(value), key)
+				return
+			}
+			encoding.write(e.w, value, tag.Transform)
+		},
+	}, nil
+}
+
+func floatCodec(tag Tag) codec {
+	var read func(r *Reader) float64
+	var write func(w Writer, v float64)
+	switch tag.Key() {
+	case "f32":
+		read = func(r *Reader) float64 { return float64(r.ReadFloat32()) }
+		write = func(w Writer, v float64) { w.WriteFloat32(float32(v)) }
+	case "f32,le":
+		read = func(r *Reader) float64 { return float64(r.ReadLEFloat32()) }
+		write = func(w Writer, v float64) { w.WriteLEFloat32(float32(v)) }
+	case "f64":
+		read = func(r *Reader) float64 { return r.ReadFloat64() }
+		write = func(w Writer, v float64) { w.WriteFloat64(v) }
+	default:
+		read = func(r *Reader) float64 { return r.ReadLEFloat64() }
+		write = func(w Writer, v float64) { w.WriteLEFloat64(v) }
+	}
+	return codec{
+		decode: func(d *decoder, v reflect.Value) {
+			d.bits = nil
+			v.SetFloat(read(d.r))
+		},
+		encode: func(e *encoder, v reflect.Value) {
+			e.bits = nil
+			write(e.w, v.Float())
+		},
+	}
+}
+
+func isSigned(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+// setInteger stores raw in v, sign extended if it was read from a signed encoding. A value that does not
+//	fit v fails the Reader with an error wrapping ErrRange instead of being truncated.
+func (d *decoder) setInteger(v reflect.Value, raw uint64, signed bool) {
+	var overflows bool
+	switch {
+	case v.Kind() == reflect.Bool:
+		v.SetBool(raw != 0)
+		return
+	case isSigned(v.Kind()):
+		overflows = !signed && int64(raw) < 0 || v.OverflowInt(int64(raw))
+	default:
+		overflows = signed && int64(raw) < 0 || v.OverflowUint(raw)
+	}
+	if overflows {
+		var value interface{} = raw
+		if signed {
+			value = int64(raw)
+		}
+		d.r.fail(fmt.Errorf("%w: %d does not fit %s", ErrRange, value, v.Type()))
+		return
+	}
+	if isSigned(v.Kind()) {
+		v.SetInt(int64(raw))
+	} else {
+		v.SetUint(raw)
+	}
+}
+
+// getInteger returns the value of v sign extended to 64 bits.
+func getInteger(v reflect.Value) uint64 {
+	switch {
+	case v.Kind() == reflect.Bool:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case isSigned(v.Kind()):
+		return uint64(v.Int())
+	}
+	return v.Uint()
+}
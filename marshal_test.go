@@ -0,0 +1,247 @@
+package bytepal
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type testPosition struct {
+	X     uint16 `bytepal:"bits=14"`
+	Y     uint16 `bytepal:"bits=14"`
+	Plane uint8  `bytepal:"bits=2"`
+	Run   bool   `bytepal:"bits=1"`
+}
+
+type testPacket struct {
+	Opcode   uint8
+	Id       uint16 `bytepal:"u16,le"`
+	Size     uint32 `bytepal:"u24"`
+	Item     uint32 `bytepal:"bigsmart"`
+	Amount   int16  `bytepal:"ssmart"`
+	Name     string `bytepal:"string,delim=10"`
+	Hidden   int    `bytepal:"-"`
+	Position testPosition
+	Colors   [3]uint8
+	Stats    [2]int16       `bytepal:"i16,le"`
+	Payload  []byte         `bytepal:"count=smart"`
+	Children []testPosition `bytepal:"count=u8"`
+	Scale    float32        `bytepal:"f32,le"`
+	Varint   int64          `bytepal:"varint"`
+	Secret   uint8          `bytepal:"u8,t=a"`
+	private  int
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	in := testPacket{
+		Opcode:   7,
+		Id:       0x1234,
+		Size:     0xABCDEF,
+		Item:     70000,
+		Amount:   -5,
+		Name:     "bytepal",
+		Hidden:   99,
+		Position: testPosition{X: 3200, Y: 3201, Plane: 2, Run: true},
+		Colors:   [3]uint8{1, 2, 3},
+		Stats:    [2]int16{-1, 300},
+		Payload:  []byte{9, 8, 7},
+		Children: []testPosition{{X: 1, Y: 2}, {X: 3, Plane: 1}},
+		Scale:    1.5,
+		Varint:   -300,
+		Secret:   200,
+	}
+	out := NewExpandableWriter()
+	require.NoError(t, Marshal(in, out))
+
+	var decoded testPacket
+	reader := NewReader(out.Payload())
+	require.NoError(t, Unmarshal(reader, &decoded))
+	assert.Equal(t, 0, reader.Remaining())
+	in.Hidden = 0
+	assert.Equal(t, in, decoded)
+}
+
+func TestMarshal_Encoding(t *testing.T) {
+	type smallPacket struct {
+		Id    uint16 `bytepal:"u16,le"`
+		Size  uint32 `bytepal:"u24"`
+		Flags uint8  `bytepal:"bits=3"`
+		Mode  uint8  `bytepal:"bits=5"`
+		Name  string `bytepal:"string,delim=10"`
+		Item  uint32 `bytepal:"bigsmart"`
+	}
+	out := NewExpandableWriter()
+	require.NoError(t, Marshal(&smallPacket{0x102, 0x30405, 5, 3, "a", 1}, out))
+	assert.Equal(t, []byte{0x2, 0x1, 0x3, 0x4, 0x5, 0xA3, 'a', 10, 0x0, 0x1}, out.Payload())
+}
+
+func TestMarshal_Range(t *testing.T) {
+	type ranged struct {
+		Small int    `bytepal:"u8"`
+		Bits  uint8  `bytepal:"bits=2"`
+		Items []byte `bytepal:"count=u8"`
+	}
+	for _, v := range []ranged{{Small: 256}, {Small: -1}, {Bits: 4}, {Items: make([]byte, 256)}} {
+		err := Marshal(v, NewExpandableWriter())
+		assert.True(t, errors.Is(err, ErrRange), "%v", err)
+	}
+	assert.NoError(t, Marshal(ranged{Small: 255, Bits: 3, Items: make([]byte, 255)}, NewExpandableWriter()))
+}
+
+func TestMarshal_Overflow(t *testing.T) {
+	err := Marshal(struct{ A uint32 }{1}, NewFixedWriter(1))
+	assert.IsType(t, &WriteError{}, err)
+}
+
+func TestUnmarshal_Short(t *testing.T) {
+	var packet testPacket
+	err := Unmarshal(NewReader([]byte{7, 0x34}), &packet)
+	assert.IsType(t, &ReadError{}, err)
+	assert.Equal(t, uint8(7), packet.Opcode)
+
+	err = Unmarshal(NewCheckedReader([]byte{7, 0x34}), &packet)
+	assert.IsType(t, &ReadError{}, err)
+
+	// A count larger than the remaining input is rejected before allocating.
+	var children struct {
+		Children []testPosition `bytepal:"count=u32"`
+	}
+	err = Unmarshal(NewReader([]byte{0xFF, 0xFF, 0xFF, 0xFF}), &children)
+	assert.IsType(t, &ReadError{}, err)
+}
+
+func TestUnmarshal_ReadFailure(t *testing.T) {
+	var varint struct {
+		V uint64 `bytepal:"uvarint"`
+	}
+	malformed := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}
+	err := Unmarshal(NewReader(malformed), &varint)
+	assert.True(t, errors.Is(err, ErrVarintOverflow), "%v", err)
+	err = Unmarshal(NewCheckedReader(malformed), &varint)
+	assert.True(t, errors.Is(err, ErrVarintOverflow), "%v", err)
+
+	r := NewReader([]byte{0x80, 0x00})
+	r.SetCanonical(true)
+	err = Unmarshal(r, &varint)
+	assert.True(t, errors.Is(err, ErrNonCanonical), "%v", err)
+}
+
+func TestUnmarshal_Range(t *testing.T) {
+	var narrow struct {
+		Small uint8 `bytepal:"u16"`
+	}
+	err := Unmarshal(NewReader([]byte{0x01, 0x00}), &narrow)
+	assert.True(t, errors.Is(err, ErrRange), "%v", err)
+	require.NoError(t, Unmarshal(NewReader([]byte{0x00, 0xFF}), &narrow))
+	assert.Equal(t, uint8(0xFF), narrow.Small)
+
+	var sign struct {
+		Signed   int8  `bytepal:"u8"`
+		Unsigned uint8 `bytepal:"i8"`
+	}
+	err = Unmarshal(NewCheckedReader([]byte{0x80, 0x00}), &sign)
+	assert.True(t, errors.Is(err, ErrRange), "%v", err)
+	err = Unmarshal(NewCheckedReader([]byte{0x7F, 0xFF}), &sign)
+	assert.True(t, errors.Is(err, ErrRange), "%v", err)
+	require.NoError(t, Unmarshal(NewCheckedReader([]byte{0x7F, 0x7F}), &sign))
+	assert.Equal(t, int8(0x7F), sign.Signed)
+	assert.Equal(t, uint8(0x7F), sign.Unsigned)
+}
+
+type testTree struct {
+	Value    uint8
+	Children []testTree `bytepal:"count=u8"`
+}
+
+func TestMarshal_Recursive(t *testing.T) {
+	in := testTree{1, []testTree{{2, nil}, {3, []testTree{{4, nil}}}}}
+	out := NewExpandableWriter()
+	require.NoError(t, Marshal(in, out))
+	assert.Equal(t, []byte{1, 2, 2, 0, 3, 1, 4, 0}, out.Payload())
+
+	var decoded testTree
+	require.NoError(t, Unmarshal(NewReader(out.Payload()), &decoded))
+	assert.Equal(t, uint8(4), decoded.Children[1].Children[0].Value)
+}
+
+func TestUnmarshal_Depth(t *testing.T) {
+	// Every level is a count of one, the input nests far deeper than decoding allows.
+	data := bytes.Repeat([]byte{1, 1}, 100000)
+	var tree testTree
+	err := Unmarshal(NewReader(data), &tree)
+	assert.True(t, errors.Is(err, ErrDepth), "%v", err)
+
+	out := NewExpandableWriter()
+	in := testTree{Value: 1}
+	for i := 0; i < maxDepth-1; i++ {
+		in = testTree{Value: 1, Children: []testTree{in}}
+	}
+	require.NoError(t, Marshal(in, out))
+	require.NoError(t, Unmarshal(NewReader(out.Payload()), &tree))
+}
+
+func TestUnmarshal_CountAllocation(t *testing.T) {
+	// The count passes Count, each element takes 2 of the remaining bytes instead of the single bit it allows.
+	var children struct {
+		Children []testTree `bytepal:"count=u32"`
+	}
+	data := append([]byte{0, 0, 0x80, 0}, make([]byte, 4096)...)
+	err := Unmarshal(NewReader(data), &children)
+	assert.IsType(t, &ReadError{}, err)
+	assert.Len(t, children.Children, 2048)
+	assert.Less(t, cap(children.Children), 32768, "the slice must grow with the elements read")
+}
+
+func TestMarshal_InvalidTags(t *testing.T) {
+	invalid := []interface{}{
+		&struct{ A []byte }{},
+		&struct {
+			A [2]byte `bytepal:"count=u8"`
+		}{},
+		&struct {
+			A string `bytepal:"u8"`
+		}{},
+		&struct {
+			A uint8 `bytepal:"string"`
+		}{},
+		&struct{ A map[int]int }{},
+		&struct {
+			A testPosition `bytepal:"u8"`
+		}{},
+	}
+	for _, v := range invalid {
+		assert.True(t, errors.Is(Unmarshal(NewReader(nil), v), ErrTag), "%T", v)
+		assert.True(t, errors.Is(Marshal(v, NewExpandableWriter()), ErrTag), "%T", v)
+	}
+	assert.Error(t, Unmarshal(NewReader(nil), testPosition{}))
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	out := NewExpandableWriter()
+	_ = Marshal(testPacket{Name: "bench", Payload: make([]byte, 16)}, out)
+	payload := out.Payload()
+	var packet testPacket
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = Unmarshal(NewReader(payload), &packet)
+	}
+}
+
+func TestTag_Integer(t *testing.T) {
+	tag, err := ParseTag("i16,le,t=a")
+	require.NoError(t, err)
+	require.True(t, tag.Integer())
+	assert.True(t, tag.Signed())
+
+	v := int64(-300)
+	out := NewExpandableWriter()
+	require.NoError(t, tag.WriteInteger(out, uint64(v)))
+	assert.True(t, errors.Is(tag.WriteInteger(out, 1<<15), ErrRange))
+	assert.Equal(t, v, int64(tag.ReadInteger(NewReader(out.Payload()))))
+
+	tag, err = ParseTag("string")
+	require.NoError(t, err)
+	assert.False(t, tag.Integer())
+}
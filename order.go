@@ -0,0 +1,106 @@
+package bytepal
+
+import "encoding/binary"
+
+// MiddleEndian is the PDP byte order, 16-bit words are stored most significant first with their bytes swapped.
+//	The int 0x0A0B0C0D is stored as 0B 0A 0D 0C.
+var MiddleEndian middleEndian
+
+// InverseMiddleEndian stores 16-bit words least significant first with their bytes in big endian order.
+//	The int 0x0A0B0C0D is stored as 0C 0D 0A 0B.
+var InverseMiddleEndian inverseMiddleEndian
+
+var _ binary.ByteOrder = MiddleEndian
+var _ binary.ByteOrder = InverseMiddleEndian
+
+type middleEndian struct{}
+
+func (middleEndian) Uint16(b []byte) uint16 {
+	_ = b[1] // bounds check hint to compiler
+	return uint16(b[0]) | uint16(b[1])<<8
+}
+
+func (middleEndian) PutUint16(b []byte, v uint16) {
+	_ = b[1]
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+}
+
+func (middleEndian) Uint32(b []byte) uint32 {
+	_ = b[3]
+	return uint32(b[2]) | uint32(b[3])<<8 | uint32(b[0])<<16 | uint32(b[1])<<24
+}
+
+func (middleEndian) PutUint32(b []byte, v uint32) {
+	_ = b[3]
+	b[0] = byte(v >> 16)
+	b[1] = byte(v >> 24)
+	b[2] = byte(v)
+	b[3] = byte(v >> 8)
+}
+
+func (middleEndian) Uint64(b []byte) uint64 {
+	_ = b[7]
+	return uint64(b[6]) | uint64(b[7])<<8 | uint64(b[4])<<16 | uint64(b[5])<<24 |
+		uint64(b[2])<<32 | uint64(b[3])<<40 | uint64(b[0])<<48 | uint64(b[1])<<56
+}
+
+func (middleEndian) PutUint64(b []byte, v uint64) {
+	_ = b[7]
+	b[0] = byte(v >> 48)
+	b[1] = byte(v >> 56)
+	b[2] = byte(v >> 32)
+	b[3] = byte(v >> 40)
+	b[4] = byte(v >> 16)
+	b[5] = byte(v >> 24)
+	b[6] = byte(v)
+	b[7] = byte(v >> 8)
+}
+
+func (middleEndian) String() string { return "MiddleEndian" }
+
+type inverseMiddleEndian struct{}
+
+func (inverseMiddleEndian) Uint16(b []byte) uint16 {
+	_ = b[1] // bounds check hint to compiler
+	return uint16(b[1]) | uint16(b[0])<<8
+}
+
+func (inverseMiddleEndian) PutUint16(b []byte, v uint16) {
+	_ = b[1]
+	b[0] = byte(v >> 8)
+	b[1] = byte(v)
+}
+
+func (inverseMiddleEndian) Uint32(b []byte) uint32 {
+	_ = b[3]
+	return uint32(b[1]) | uint32(b[0])<<8 | uint32(b[3])<<16 | uint32(b[2])<<24
+}
+
+func (inverseMiddleEndian) PutUint32(b []byte, v uint32) {
+	_ = b[3]
+	b[0] = byte(v >> 8)
+	b[1] = byte(v)
+	b[2] = byte(v >> 24)
+	b[3] = byte(v >> 16)
+}
+
+func (inverseMiddleEndian) Uint64(b []byte) uint64 {
+	_ = b[7]
+	return uint64(b[1]) | uint64(b[0])<<8 | uint64(b[3])<<16 | uint64(b[2])<<24 |
+		uint64(b[5])<<32 | uint64(b[4])<<40 | uint64(b[7])<<48 | uint64(b[6])<<56
+}
+
+func (inverseMiddleEndian) PutUint64(b []byte, v uint64) {
+	_ = b[7]
+	b[0] = byte(v >> 8)
+	b[1] = byte(v)
+	b[2] = byte(v >> 24)
+	b[3] = byte(v >> 16)
+	b[4] = byte(v >> 40)
+	b[5] = byte(v >> 32)
+	b[6] = byte(v >> 56)
+	b[7] = byte(v >> 48)
+}
+
+func (inverseMiddleEndian) String() string { return "InverseMiddleEndian" }
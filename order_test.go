@@ -0,0 +1,86 @@
+package bytepal
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func testByteOrder(t *testing.T, order binary.ByteOrder, b16, b32, b64 []byte) {
+	assert.Equal(t, uint16(0x0102), order.Uint16(b16))
+	assert.Equal(t, uint32(0x01020304), order.Uint32(b32))
+	assert.Equal(t, uint64(0x0102030405060708), order.Uint64(b64))
+
+	data := make([]byte, 8)
+	order.PutUint16(data, 0x0102)
+	assert.Equal(t, b16, data[:2])
+	order.PutUint32(data, 0x01020304)
+	assert.Equal(t, b32, data[:4])
+	order.PutUint64(data, 0x0102030405060708)
+	assert.Equal(t, b64, data)
+}
+
+func TestMiddleEndian(t *testing.T) {
+	testByteOrder(t, MiddleEndian,
+		[]byte{2, 1},
+		[]byte{2, 1, 4, 3},
+		[]byte{2, 1, 4, 3, 6, 5, 8, 7},
+	)
+}
+
+func TestInverseMiddleEndian(t *testing.T) {
+	testByteOrder(t, InverseMiddleEndian,
+		[]byte{1, 2},
+		[]byte{3, 4, 1, 2},
+		[]byte{7, 8, 5, 6, 3, 4, 1, 2},
+	)
+}
+
+func TestReader_ReadMEUInt32(t *testing.T) {
+	reader := NewReader([]byte{0x0B, 0x0A, 0x0D, 0x0C, 0x0C, 0x0D, 0x0A, 0x0B})
+	assert.Equal(t, uint32(0x0A0B0C0D), reader.ReadMEUInt32())
+	assert.Equal(t, uint32(0x0A0B0C0D), reader.ReadIMEUInt32())
+}
+
+func testOrderedRoundTrip(t *testing.T, out Writer, order binary.ByteOrder) {
+	out.WriteInt16(-2)
+	out.WriteUInt32(0x01020304)
+	out.WriteInt64(-3)
+	out.WriteFloat32(1.5)
+	out.WriteBigSmart(40000)
+	require.NoError(t, out.Err())
+
+	expected := make([]byte, 14)
+	order.PutUint16(expected, 0xFFFE)
+	order.PutUint32(expected[2:], 0x01020304)
+	order.PutUint64(expected[6:], 0xFFFFFFFFFFFFFFFD)
+	expected = append(expected, 0, 0, 0, 0, 0x80, 0x00, 0x9C, 0x40)
+	order.PutUint32(expected[14:], math.Float32bits(1.5))
+	assert.Equal(t, expected, out.Payload())
+
+	in := NewReaderWithOrder(out.Payload(), order)
+	assert.Equal(t, order, in.Order())
+	assert.Equal(t, int16(-2), in.ReadInt16())
+	assert.Equal(t, uint32(0x01020304), in.ReadUInt32())
+	assert.Equal(t, int64(-3), in.ReadInt64())
+	assert.Equal(t, float32(1.5), in.ReadFloat32())
+	assert.Equal(t, uint32(40000), in.ReadBigSmartValue())
+}
+
+func TestWriter_Order(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian, MiddleEndian, InverseMiddleEndian} {
+		t.Run(order.String(), func(t *testing.T) {
+			testOrderedRoundTrip(t, NewExpandableWriterWithOrder(order), order)
+			testOrderedRoundTrip(t, NewFixedWriterWithOrder(22, order), order)
+			testOrderedRoundTrip(t, NewFixedWriterWithOrderAndPolicy(1, order, OverflowGrow), order)
+		})
+	}
+
+	out := NewFixedWriterWithOrderAndPolicy(3, binary.LittleEndian, OverflowError)
+	out.WriteUInt16(0x0102)
+	out.WriteUInt16(0x0304)
+	assert.Equal(t, []byte{2, 1, 0}, out.Payload())
+	assert.IsType(t, &WriteError{}, out.Err())
+}
@@ -3,34 +3,92 @@ package bytepal
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
+	"math"
 )
 
 var bitMask [32]uint
 
+// ReadError is returned when a read requests more bytes than are left in the payload.
+//	It unwraps to io.ErrUnexpectedEOF so callers may check it with errors.Is.
+type ReadError struct {
+	Offset    int
+	Size      int
+	Available int
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("bytepal: read of %d bytes at offset %d, only %d available", e.Size, e.Offset, e.Available)
+}
+
+// Unwrap returns io.ErrUnexpectedEOF
+func (e *ReadError) Unwrap() error {
+	return io.ErrUnexpectedEOF
+}
+
 // Wrapper that will read incremental bytes of an array into variables
 type Reader struct {
 	bytes        []byte
 	currentIndex int
+	order        binary.ByteOrder
+	// bigEndian is set for binary.BigEndian, whose reads skip the call through order.
+	bigEndian bool
+
+	// limit is the offset reads may not pass, limits holds the ones restored by PopLimit.
+	limit  int
+	limits []int
+
+	// checked readers record a sticky error instead of panicking on short input.
+	checked   bool
+	canonical bool
+	err       error
+	scratch   [8]byte
 }
 
 // Create a Reader from a existing byte array with endianess set to BigEndian.
 func NewReader(bytes []byte) *Reader {
+	return NewReaderWithOrder(bytes, binary.BigEndian)
+}
+
+// NewReaderWithOrder creates a Reader where ReadUInt16, ReadUInt32, ReadUInt64 and their signed
+//	and float variants follow the given byte order.
+func NewReaderWithOrder(bytes []byte, order binary.ByteOrder) *Reader {
 	return &Reader{
-		bytes: bytes,
+		bytes:     bytes,
+		order:     order,
+		bigEndian: order == binary.BigEndian,
+		limit:     len(bytes),
 	}
 }
 
-// Reads bytes and creates a new reader.
+// NewCheckedReader creates a Reader that never panics on short input. The first failed read is recorded
+//	and returned by Err, every read after it returns zero values.
+func NewCheckedReader(bytes []byte) *Reader {
+	reader := NewReader(bytes)
+	reader.checked = true
+	return reader
+}
+
+// Reads exactly size bytes and creates a new reader. A reader ending early returns io.ErrUnexpectedEOF.
 func ReadBytes(reader io.Reader, size int) (*Reader, error) {
 	bytes := make([]byte, size)
-	_, err := reader.Read(bytes)
-	if err != nil {
+	if _, err := io.ReadFull(reader, bytes); err != nil {
 		return nil, err
 	}
-	return &Reader{
-		bytes: bytes,
-	}, nil
+	return NewReader(bytes), nil
+}
+
+// derive creates a Reader over bytes sharing the settings of this one.
+func (b *Reader) derive(bytes []byte) *Reader {
+	reader := NewReaderWithOrder(bytes, b.order)
+	reader.checked, reader.canonical = b.checked, b.canonical
+	return reader
+}
+
+// Order returns the byte order used by ReadUInt16, ReadUInt32 and ReadUInt64.
+func (b *Reader) Order() binary.ByteOrder {
+	return b.order
 }
 
 // Payload returns the underlying byte array
@@ -38,100 +96,315 @@ func (b *Reader) Payload() []byte {
 	return b.bytes
 }
 
-// Seek sets the reading index to the desired position. NOTE: No out of bounds checks are performed.
-func (b *Reader) Seek(position int) {
+// Err returns the first error encountered while reading, or nil.
+func (b *Reader) Err() error {
+	return b.err
+}
+
+// Checked reports whether the Reader records a sticky error instead of panicking on short input.
+func (b *Reader) Checked() bool {
+	return b.checked
+}
+
+// SetChecked switches the Reader to or from the checked mode of NewCheckedReader. Leaving checked mode
+//	clears the recorded error.
+func (b *Reader) SetChecked(checked bool) {
+	b.checked = checked
+	if !checked {
+		b.err = nil
+	}
+}
+
+// CurrentRead returns the reading index.
+func (b *Reader) CurrentRead() int {
+	return b.currentIndex
+}
+
+// SetCurrentRead sets the reading index to the desired position.
+//	NOTE: No out of bounds checks are performed unless the Reader was created with NewCheckedReader.
+func (b *Reader) SetCurrentRead(position int) {
+	if b.checked && (position < 0 || position > b.limit) {
+		b.fail(&ReadError{Offset: position, Available: b.limit})
+		return
+	}
 	b.currentIndex = position
 }
 
+// fail records err as the sticky error of a checked Reader, an unchecked Reader panics with it instead.
+func (b *Reader) fail(err error) {
+	if !b.checked {
+		panic(err)
+	}
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// take advances the index pointer by size and returns the bytes passed over. An unchecked Reader slices
+//	directly, so take stays inlinable and a short read panics with the runtime's index error.
+func (b *Reader) take(size int) []byte {
+	if b.checked {
+		return b.takeChecked(size)
+	}
+	b.currentIndex += size
+	return b.bytes[b.currentIndex-size : b.currentIndex : b.limit]
+}
+
+// takeChecked is take for a checked Reader, a short read records the sticky error and returns zeroed bytes.
+func (b *Reader) takeChecked(size int) []byte {
+	if b.err == nil && uint(size) <= uint(b.limit-b.currentIndex) {
+		b.currentIndex += size
+		return b.bytes[b.currentIndex-size : b.currentIndex]
+	}
+	if b.err == nil {
+		b.fail(&ReadError{Offset: b.currentIndex, Size: size, Available: b.Remaining()})
+	}
+	if size < 0 {
+		size = 0
+	}
+	if size <= len(b.scratch) {
+		b.scratch = [8]byte{}
+		return b.scratch[:size]
+	}
+	return make([]byte, size)
+}
+
+// peek returns the next byte without moving the index pointer.
+func (b *Reader) peek() byte {
+	if b.checked {
+		return b.peekChecked()
+	}
+	return b.bytes[:b.limit][b.currentIndex]
+}
+
+// peekChecked is peek for a checked Reader, a short read records the sticky error and returns zero.
+func (b *Reader) peekChecked() byte {
+	if b.err == nil && b.currentIndex < b.limit {
+		return b.bytes[b.currentIndex]
+	}
+	b.takeChecked(1)
+	return 0
+}
+
+// uint16 decodes data in the byte order of the Reader, big endian skips the call through the interface.
+func (b *Reader) uint16(data []byte) uint16 {
+	if b.bigEndian {
+		return binary.BigEndian.Uint16(data)
+	}
+	return b.order.Uint16(data)
+}
+
+// uint32 decodes data in the byte order of the Reader.
+func (b *Reader) uint32(data []byte) uint32 {
+	if b.bigEndian {
+		return binary.BigEndian.Uint32(data)
+	}
+	return b.order.Uint32(data)
+}
+
+// uint64 decodes data in the byte order of the Reader.
+func (b *Reader) uint64(data []byte) uint64 {
+	if b.bigEndian {
+		return binary.BigEndian.Uint64(data)
+	}
+	return b.order.Uint64(data)
+}
+
 // Reads a single byte off the array and increments the index pointer
 func (b *Reader) ReadUInt8() uint8 {
-	//defer lastResult.Inc(1)
-	b.currentIndex++
-	return b.bytes[b.currentIndex-1]
+	return b.take(1)[0]
+}
+
+// ReadInt8 reads a single signed byte off the array and increments the index pointer
+func (b *Reader) ReadInt8() int8 {
+	return int8(b.take(1)[0])
 }
 
 // ReadSlice reads the given number of bytes and returns a slice of the payload containing those
 func (b *Reader) ReadSlice(size int) []byte {
-	data := b.bytes[b.currentIndex:b.currentIndex+size]
-	b.currentIndex += size
+	data := b.take(size)
+	if b.err != nil {
+		return nil
+	}
 	return data
 }
 
 func (b *Reader) ReadBytes(payload []byte) {
-	for i := range payload {
-		payload[i] = b.bytes[b.currentIndex+i]
-	}
-	b.currentIndex += len(payload)
+	copy(payload, b.take(len(payload)))
 }
 
 // Reads a twos byte off the array and increments the index pointer
 func (b *Reader) ReadLEUInt16() uint16 {
-	b.currentIndex += 2
-	return binary.LittleEndian.Uint16(b.bytes[b.currentIndex-2 : b.currentIndex])
+	return binary.LittleEndian.Uint16(b.take(2))
+}
+
+// ReadLEInt16 reads a signed short in little endian order
+func (b *Reader) ReadLEInt16() int16 {
+	return int16(binary.LittleEndian.Uint16(b.take(2)))
 }
 
 // Reads a twos byte off the array and increments the index pointer
 func (b *Reader) ReadUInt16() uint16 {
-	b.currentIndex += 2
-	return binary.BigEndian.Uint16(b.bytes[b.currentIndex-2 : b.currentIndex])
+	return b.uint16(b.take(2))
+}
+
+// ReadInt16 reads a signed short in the byte order of the Reader
+func (b *Reader) ReadInt16() int16 {
+	return int16(b.uint16(b.take(2)))
 }
 
 // ReadUMedium reads a 24bit unsigned value
 func (b *Reader) ReadUMedium() uint32 {
-	b.currentIndex += 3
-	return uint32(b.bytes[b.currentIndex-3]) << 16 | uint32(b.bytes[b.currentIndex-2]) << 8 | uint32(b.bytes[b.currentIndex-1])
+	data := b.take(3)
+	return uint32(data[0])<<16 | uint32(data[1])<<8 | uint32(data[2])
+}
+
+// ReadMedium reads a 24bit signed value, the sign bit is extended into the upper byte.
+func (b *Reader) ReadMedium() int32 {
+	return int32(b.ReadUMedium()<<8) >> 8
+}
+
+// ReadLEUMedium reads a 24bit unsigned value in little endian order
+func (b *Reader) ReadLEUMedium() uint32 {
+	data := b.take(3)
+	return uint32(data[2])<<16 | uint32(data[1])<<8 | uint32(data[0])
+}
+
+// ReadLEMedium reads a 24bit signed value in little endian order
+func (b *Reader) ReadLEMedium() int32 {
+	return int32(b.ReadLEUMedium()<<8) >> 8
 }
 
 // ReadBigSmart attempts to read either a short or int based on the next value.
+//	The flag bit of the int form is kept, ReadBigSmartValue clears it.
 func (b *Reader) ReadBigSmart() uint32 {
-	if int8(b.bytes[b.currentIndex]) >= 0 {
-		return uint32(b.ReadUInt16())
+	if int8(b.peek()) >= 0 {
+		return uint32(binary.BigEndian.Uint16(b.take(2)))
 	}
-	return b.ReadUInt32()
+	return binary.BigEndian.Uint32(b.take(4))
 }
 
 // Reads a twos byte off the array and increments the index pointer
 func (b *Reader) ReadLEUInt32() uint32 {
-	b.currentIndex += 4
-	return binary.LittleEndian.Uint32(b.bytes[b.currentIndex-4 : b.currentIndex])
+	return binary.LittleEndian.Uint32(b.take(4))
+}
+
+// ReadLEInt32 reads a signed int in little endian order
+func (b *Reader) ReadLEInt32() int32 {
+	return int32(binary.LittleEndian.Uint32(b.take(4)))
 }
 
 // Reads a twos byte off the array and increments the index pointer
 func (b *Reader) ReadUInt32() uint32 {
-	b.currentIndex += 4
-	return binary.BigEndian.Uint32(b.bytes[b.currentIndex-4 : b.currentIndex])
+	return b.uint32(b.take(4))
+}
+
+// ReadInt32 reads a signed int in the byte order of the Reader
+func (b *Reader) ReadInt32() int32 {
+	return int32(b.uint32(b.take(4)))
+}
+
+// ReadMEUInt32 reads an int in middle endian order, the bytes 0A 0B 0C 0D are read as 0x0B0A0D0C.
+func (b *Reader) ReadMEUInt32() uint32 {
+	return MiddleEndian.Uint32(b.take(4))
+}
+
+// ReadMEInt32 reads a signed int in middle endian order
+func (b *Reader) ReadMEInt32() int32 {
+	return int32(MiddleEndian.Uint32(b.take(4)))
+}
+
+// ReadIMEUInt32 reads an int in inverse middle endian order, the bytes 0A 0B 0C 0D are read as 0x0C0D0A0B.
+func (b *Reader) ReadIMEUInt32() uint32 {
+	return InverseMiddleEndian.Uint32(b.take(4))
+}
+
+// ReadIMEInt32 reads a signed int in inverse middle endian order
+func (b *Reader) ReadIMEInt32() int32 {
+	return int32(InverseMiddleEndian.Uint32(b.take(4)))
 }
 
 // Reads a twos byte off the array and increments the index pointer
 func (b *Reader) ReadLEUInt64() uint64 {
-	b.currentIndex += 8
-	return binary.LittleEndian.Uint64(b.bytes[b.currentIndex-8 : b.currentIndex])
+	return binary.LittleEndian.Uint64(b.take(8))
+}
+
+// ReadLEInt64 reads a signed long in little endian order
+func (b *Reader) ReadLEInt64() int64 {
+	return int64(binary.LittleEndian.Uint64(b.take(8)))
 }
 
 // Reads a twos byte off the array and increments the index pointer
 func (b *Reader) ReadUInt64() uint64 {
-	b.currentIndex += 8
-	return binary.BigEndian.Uint64(b.bytes[b.currentIndex-8 : b.currentIndex])
+	return b.uint64(b.take(8))
+}
+
+// ReadInt64 reads a signed long in the byte order of the Reader
+func (b *Reader) ReadInt64() int64 {
+	return int64(b.uint64(b.take(8)))
+}
+
+// ReadFloat32 reads an IEEE-754 single precision float in the byte order of the Reader
+func (b *Reader) ReadFloat32() float32 {
+	return math.Float32frombits(b.uint32(b.take(4)))
+}
+
+// ReadLEFloat32 reads an IEEE-754 single precision float in little endian order
+func (b *Reader) ReadLEFloat32() float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(b.take(4)))
+}
+
+// ReadFloat64 reads an IEEE-754 double precision float in the byte order of the Reader
+func (b *Reader) ReadFloat64() float64 {
+	return math.Float64frombits(b.uint64(b.take(8)))
+}
+
+// ReadLEFloat64 reads an IEEE-754 double precision float in little endian order
+func (b *Reader) ReadLEFloat64() float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(b.take(8)))
 }
 
 // Remaining bytes available to be read.
 func (b *Reader) Remaining() int {
-	return len(b.bytes) - b.currentIndex
+	return b.limit - b.currentIndex
+}
+
+// Count checks an element count that was read before allocating for it. Every element takes at least
+//	a bit, so a count past the remaining bits is a ReadError and 0 is returned.
+func (b *Reader) Count(n uint64) int {
+	if b.err != nil {
+		return 0
+	}
+	if n > uint64(b.Remaining())*8 {
+		b.fail(&ReadError{Offset: b.currentIndex, Size: int((n + 7) / 8), Available: b.Remaining()})
+		return 0
+	}
+	return int(n)
 }
 
 // Continuously reads bytes until the deliminiter character is read.
+//	A missing delimiter is reported as a ReadError on a checked Reader.
 func (b *Reader) ReadString(delim byte) string {
-	if index := bytes.IndexByte(b.bytes[b.currentIndex:], delim); index > 0 {
-		end := index + b.currentIndex
-		data := b.bytes[b.currentIndex:end]
-		b.currentIndex = end + 1
-		return string(data)
+	if b.err == nil && b.currentIndex <= b.limit {
+		if index := bytes.IndexByte(b.bytes[b.currentIndex:b.limit], delim); index >= 0 {
+			end := index + b.currentIndex
+			data := b.bytes[b.currentIndex:end]
+			b.currentIndex = end + 1
+			return string(data)
+		}
+	}
+	if b.checked && b.err == nil {
+		b.fail(&ReadError{Offset: b.currentIndex, Size: b.Remaining() + 1, Available: b.Remaining()})
 	}
 	return ""
 }
 
 // Increments the index pointer by the amount
 func (b *Reader) Inc(amt int) {
+	if b.checked {
+		b.SetCurrentRead(b.currentIndex + amt)
+		return
+	}
 	b.currentIndex += amt
 }
 
@@ -139,6 +412,12 @@ func (b *Reader) Inc(amt int) {
 func (b *Reader) ReadBits() func(uint) uint {
 	bitPosition := uint(b.currentIndex * 8)
 	return func(numBits uint) uint {
+		if start, end := int(bitPosition>>3), int(bitPosition+numBits+7)/8; b.err != nil || end > b.limit {
+			if b.err == nil {
+				b.fail(&ReadError{Offset: start, Size: end - start, Available: b.limit - start})
+			}
+			return 0
+		}
 		bytePos := bitPosition >> 3
 		bitOffset := 8 - (bitPosition & 7)
 		bitPosition += numBits
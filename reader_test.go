@@ -1,7 +1,10 @@
 package bytepal
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
 	"testing"
 )
 
@@ -115,4 +118,85 @@ func TestArrayReader_ReadBits(t *testing.T) {
 	assert.Equal(t, uint(1), r(5))
 	assert.Equal(t, uint(1), r(1))
 	assert.Equal(t, uint(3), r(15))
+	assert.Equal(t, 3, in.CurrentRead())
+}
+
+func TestReader_Checked(t *testing.T) {
+	reader := NewCheckedReader([]byte{0x1, 0x2, 0x3})
+	assert.Equal(t, uint16(0x102), reader.ReadUInt16())
+	assert.NoError(t, reader.Err())
+
+	assert.Equal(t, uint32(0), reader.ReadUInt32())
+	require.Error(t, reader.Err())
+	assert.True(t, errors.Is(reader.Err(), io.ErrUnexpectedEOF))
+
+	var readErr *ReadError
+	require.True(t, errors.As(reader.Err(), &readErr))
+	assert.Equal(t, 2, readErr.Offset)
+	assert.Equal(t, 4, readErr.Size)
+	assert.Equal(t, 1, readErr.Available)
+
+	// The error is sticky, even reads that would fit return zero values.
+	assert.Equal(t, uint8(0), reader.ReadUInt8())
+	assert.Nil(t, reader.ReadSlice(1))
+	assert.Equal(t, readErr, reader.Err())
+}
+
+func TestReader_CheckedNoPanic(t *testing.T) {
+	reads := map[string]func(r *Reader){
+		"ReadUInt8":      func(r *Reader) { r.ReadUInt8() },
+		"ReadUInt16":     func(r *Reader) { r.ReadUInt16() },
+		"ReadLEUInt32":   func(r *Reader) { r.ReadLEUInt32() },
+		"ReadUInt64":     func(r *Reader) { r.ReadUInt64() },
+		"ReadUMedium":    func(r *Reader) { r.ReadUMedium() },
+		"ReadBigSmart":   func(r *Reader) { r.ReadBigSmart() },
+		"ReadSlice":      func(r *Reader) { r.ReadSlice(2) },
+		"ReadBytes":      func(r *Reader) { r.ReadBytes(make([]byte, 16)) },
+		"ReadString":     func(r *Reader) { r.ReadString(Delim) },
+		"ReadBits":       func(r *Reader) { r.ReadBits()(9) },
+		"SetCurrentRead": func(r *Reader) { r.SetCurrentRead(2) },
+		"Inc":            func(r *Reader) { r.Inc(-1) },
+	}
+	for name, read := range reads {
+		reader := NewCheckedReader([]byte{})
+		assert.NotPanics(t, func() { read(reader) }, name)
+		assert.Error(t, reader.Err(), name)
+	}
+}
+
+func TestReader_UncheckedPanics(t *testing.T) {
+	reader := NewReader([]byte{0x1})
+	assert.Panics(t, func() { reader.ReadUInt16() })
+
+	reader = NewReader([]byte{0x1, 0x2})
+	reader.PushLimit(1)
+	assert.Panics(t, func() { reader.ReadUInt16() })
+}
+
+func TestReader_SetChecked(t *testing.T) {
+	reader := NewReader([]byte{0x1})
+	reader.SetChecked(true)
+	assert.True(t, reader.Checked())
+	assert.Equal(t, uint16(0), reader.ReadUInt16())
+	assert.IsType(t, &ReadError{}, reader.Err())
+
+	reader.SetChecked(false)
+	assert.False(t, reader.Checked())
+	assert.NoError(t, reader.Err())
+	assert.Equal(t, uint8(0x1), reader.ReadUInt8())
+}
+
+func TestReadString_Empty(t *testing.T) {
+	reader := NewCheckedReader([]byte{Delim, 'a', Delim})
+	assert.Equal(t, "", reader.ReadString(Delim))
+	assert.Equal(t, "a", reader.ReadString(Delim))
+	assert.NoError(t, reader.Err())
+}
+
+func TestReader_ReadMedium(t *testing.T) {
+	reader := NewReader([]byte{0xFF, 0xFF, 0xFE, 0x01, 0x02, 0x03, 0xFE, 0xFF, 0xFF, 0x03, 0x02, 0x01})
+	assert.Equal(t, int32(-2), reader.ReadMedium())
+	assert.Equal(t, uint32(0x10203), reader.ReadUMedium())
+	assert.Equal(t, int32(-2), reader.ReadLEMedium())
+	assert.Equal(t, uint32(0x10203), reader.ReadLEUMedium())
 }
@@ -0,0 +1,49 @@
+package bytepal
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// ErrRSABlockSize is returned when an RSA block is not smaller than the modulus it is encrypted with.
+var ErrRSABlockSize = errors.New("bytepal: RSA block does not fit the modulus")
+
+// ReadRSABlock reads a block prefixed with its length as a big endian short and decrypts it with textbook RSA,
+//	no padding is removed. The returned Reader shares the settings of this one.
+//	NOTE: Leading zero bytes of the decrypted block are lost.
+func (b *Reader) ReadRSABlock(modulus, exponent *big.Int) *Reader {
+	size := int(binary.BigEndian.Uint16(b.take(2)))
+	block := new(big.Int).SetBytes(b.take(size))
+
+	sub := b.derive(nil)
+	if b.err != nil {
+		sub.err = b.err
+		return sub
+	}
+	if block.Cmp(modulus) >= 0 {
+		b.fail(ErrRSABlockSize)
+		sub.err = b.err
+		return sub
+	}
+	sub.bytes = block.Exp(block, exponent, modulus).Bytes()
+	sub.limit = len(sub.bytes)
+	return sub
+}
+
+// EncryptRSABlock encrypts the payload of a finished Writer with textbook RSA and returns it prefixed with
+//	its length as a big endian short, ready to be written.
+func EncryptRSABlock(block Writer, modulus, exponent *big.Int) ([]byte, error) {
+	if err := block.Err(); err != nil {
+		return nil, err
+	}
+	value := new(big.Int).SetBytes(block.Payload())
+	if value.Cmp(modulus) >= 0 {
+		return nil, ErrRSABlockSize
+	}
+	encrypted := value.Exp(value, exponent, modulus).Bytes()
+	data := make([]byte, 2+len(encrypted))
+	binary.BigEndian.PutUint16(data, uint16(len(encrypted)))
+	copy(data[2:], encrypted)
+	return data, nil
+}
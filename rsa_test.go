@@ -0,0 +1,56 @@
+package bytepal
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestRSABlock(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	publicExponent := big.NewInt(int64(key.E))
+
+	block := NewExpandableWriter()
+	block.WriteUInt8(10)
+	block.WriteInt32(0x12345678)
+	block.WriteString("password", 10)
+
+	encrypted, err := EncryptRSABlock(block, key.N, publicExponent)
+	require.NoError(t, err)
+
+	out := NewExpandableWriter()
+	out.WriteUInt8(0xAA)
+	out.Write(encrypted)
+	out.WriteUInt8(0xBB)
+
+	in := NewCheckedReader(out.Payload())
+	assert.Equal(t, uint8(0xAA), in.ReadUInt8())
+	sub := in.ReadRSABlock(key.N, key.D)
+	assert.Equal(t, uint8(0xBB), in.ReadUInt8())
+	require.NoError(t, in.Err())
+
+	assert.Equal(t, uint8(10), sub.ReadUInt8())
+	assert.Equal(t, uint32(0x12345678), sub.ReadUInt32())
+	assert.Equal(t, "password", sub.ReadString(10))
+	assert.NoError(t, sub.Err())
+	assert.Equal(t, 0, sub.Remaining())
+}
+
+func TestRSABlock_TooLarge(t *testing.T) {
+	modulus := big.NewInt(3233)
+	block := NewExpandableWriter()
+	block.WriteInt16(3233)
+	_, err := EncryptRSABlock(block, modulus, big.NewInt(17))
+	assert.True(t, errors.Is(err, ErrRSABlockSize))
+
+	in := NewCheckedReader([]byte{0, 2, 0x0C, 0xA1})
+	sub := in.ReadRSABlock(modulus, big.NewInt(413))
+	assert.True(t, errors.Is(in.Err(), ErrRSABlockSize))
+	assert.Equal(t, uint8(0), sub.ReadUInt8())
+	assert.Error(t, sub.Err())
+}
@@ -0,0 +1,218 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// expr is an integer expression over the fields decoded so far, booleans are 1 and 0.
+type expr interface {
+	eval(sc *scope) (int64, error)
+}
+
+type literal int64
+
+type name []string
+
+type unary struct {
+	op string
+	x  expr
+}
+
+type binary struct {
+	op   string
+	x, y expr
+}
+
+// Binary operators by precedence, lowest first, as in Go.
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"+": 4, "-": 4, "|": 4, "^": 4,
+	"*": 5, "/": 5, "%": 5, "<<": 5, ">>": 5, "&": 5,
+}
+
+func (p *parser) expr() expr {
+	return p.binary(1)
+}
+
+func (p *parser) binary(min int) expr {
+	x := p.unary()
+	for {
+		prec, ok := precedence[p.tok.text]
+		if p.tok.kind != tokenPunct || !ok || prec < min {
+			return x
+		}
+		op := p.tok.text
+		p.next()
+		x = &binary{op: op, x: x, y: p.binary(prec + 1)}
+	}
+}
+
+func (p *parser) unary() expr {
+	if p.tok.kind == tokenPunct && (p.tok.text == "!" || p.tok.text == "-" || p.tok.text == "^") {
+		op := p.tok.text
+		p.next()
+		return &unary{op: op, x: p.unary()}
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() expr {
+	switch {
+	case p.accept("("):
+		x := p.expr()
+		p.expect(")")
+		return x
+	case p.tok.kind == tokenInt:
+		return literal(p.integer())
+	case p.tok.kind == tokenIdent:
+		n := name{p.ident()}
+		for p.accept(".") {
+			n = append(n, p.ident())
+		}
+		return n
+	}
+	p.fail("expected an expression, found %q", p.tok.text)
+	return nil
+}
+
+func (l literal) eval(*scope) (int64, error) {
+	return int64(l), nil
+}
+
+func (n name) eval(sc *scope) (int64, error) {
+	v, ok := sc.lookup(n[0])
+	for _, part := range n[1:] {
+		if !ok {
+			break
+		}
+		var m map[string]interface{}
+		if m, ok = v.(map[string]interface{}); ok {
+			v, ok = m[part]
+		}
+	}
+	if !ok {
+		return 0, fmt.Errorf("unknown field %s", strings.Join(n, "."))
+	}
+	i, err := toInt64(v)
+	if err != nil {
+		return 0, fmt.Errorf("field %s: %v", strings.Join(n, "."), err)
+	}
+	return i, nil
+}
+
+func (u *unary) eval(sc *scope) (int64, error) {
+	x, err := u.x.eval(sc)
+	switch u.op {
+	case "!":
+		return boolean(x == 0), err
+	case "^":
+		return ^x, err
+	}
+	return -x, err
+}
+
+func (b *binary) eval(sc *scope) (int64, error) {
+	x, err := b.x.eval(sc)
+	if err != nil {
+		return 0, err
+	}
+	// The right hand side of && and || is only evaluated when needed, as in Go.
+	switch {
+	case b.op == "&&" && x == 0:
+		return 0, nil
+	case b.op == "||" && x != 0:
+		return 1, nil
+	}
+	y, err := b.y.eval(sc)
+	if err != nil {
+		return 0, err
+	}
+	switch b.op {
+	case "&&", "||":
+		return boolean(y != 0), nil
+	case "==":
+		return boolean(x == y), nil
+	case "!=":
+		return boolean(x != y), nil
+	case "<":
+		return boolean(x < y), nil
+	case "<=":
+		return boolean(x <= y), nil
+	case ">":
+		return boolean(x > y), nil
+	case ">=":
+		return boolean(x >= y), nil
+	case "+":
+		return x + y, nil
+	case "-":
+		return x - y, nil
+	case "|":
+		return x | y, nil
+	case "^":
+		return x ^ y, nil
+	case "*":
+		return x * y, nil
+	case "&":
+		return x & y, nil
+	case "<<", ">>":
+		if y < 0 {
+			return 0, fmt.Errorf("negative shift %d", y)
+		}
+		if b.op == "<<" {
+			return x << uint64(y), nil
+		}
+		return x >> uint64(y), nil
+	}
+	if y == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+	if b.op == "/" {
+		return x / y, nil
+	}
+	return x % y, nil
+}
+
+func boolean(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// scope holds the fields of a struct, names not found are looked up in the enclosing struct.
+type scope struct {
+	values map[string]interface{}
+	parent *scope
+}
+
+func (sc *scope) lookup(n string) (interface{}, bool) {
+	for ; sc != nil; sc = sc.parent {
+		if v, ok := sc.values[n]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// toInt64 converts any Go integer, integral float or bool to an int64.
+func toInt64(v interface{}) (int64, error) {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Bool:
+		return boolean(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(value.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		if f := value.Float(); f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return int64(f), nil
+		}
+	}
+	return 0, fmt.Errorf("%v (%T) is not an integer", v, v)
+}
@@ -0,0 +1,484 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/Pwalne/bytepal"
+	"github.com/Pwalne/bytepal/hexdump"
+)
+
+// OpcodeKey holds the opcode of each entry decoded from an opcode block.
+const OpcodeKey = "opcode"
+
+// maxDepth bounds the nesting of decoded structs like bytepal.Unmarshal, a struct that holds itself
+//	would otherwise recurse until the stack runs out.
+const maxDepth = 256
+
+type decoder struct {
+	r *bytepal.Reader
+	// bits is shared by consecutive bit fields, bit is the position of the next one.
+	bits func(uint) uint
+	bit  int
+	// depth counts the nested structs being decoded.
+	depth int
+
+	// spans records the bytes of every value when set, path names the value being decoded.
+	//	The path is only built along with the spans.
+	spans *[]hexdump.Span
+	path  string
+}
+
+type encoder struct {
+	w    bytepal.Writer
+	bits func(uint, uint)
+}
+
+// Decode reads the struct root from r. A short read is returned as a bytepal.ReadError and any other read
+//	failure, such as a malformed varint, as its error, even if r was not created with bytepal.NewCheckedReader.
+//	Structs nested deeper than 256 levels return bytepal.ErrDepth.
+func (s *Schema) Decode(r *bytepal.Reader, root string) (map[string]interface{}, error) {
+	return s.decode(&decoder{r: r}, root)
+}
+
+// DecodeSpans decodes like Decode and also returns a span for every value read, named by its path such
+//	as "items[2].amount". The spans read before a failure are returned with the error.
+func (s *Schema) DecodeSpans(r *bytepal.Reader, root string) (map[string]interface{}, []hexdump.Span, error) {
+	spans := []hexdump.Span{}
+	v, err := s.decode(&decoder{r: r, spans: &spans}, root)
+	return v, spans, err
+}
+
+func (s *Schema) decode(d *decoder, root string) (v map[string]interface{}, err error) {
+	r := d.r
+	def, ok := s.structs[root]
+	if !ok {
+		return nil, fmt.Errorf("schema: unknown struct %s", root)
+	}
+	if !r.Checked() {
+		// Decoding in checked mode returns the failures of an unchecked Reader instead of panicking.
+		r.SetChecked(true)
+		defer r.SetChecked(false)
+	}
+	v, err = s.decodeStruct(d, def, nil)
+	if err == nil {
+		err = r.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Encode writes v as the struct root to w. A FixedWriter that overflows returns its bytepal.WriteError.
+func (s *Schema) Encode(w bytepal.Writer, root string, v map[string]interface{}) (err error) {
+	def, ok := s.structs[root]
+	if !ok {
+		return fmt.Errorf("schema: unknown struct %s", root)
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			writeErr, ok := rec.(*bytepal.WriteError)
+			if !ok {
+				panic(rec)
+			}
+			err = writeErr
+		}
+	}()
+	if err := s.encodeStruct(&encoder{w: w}, def, v, nil); err != nil {
+		return err
+	}
+	return w.Err()
+}
+
+func (s *Schema) decodeStruct(d *decoder, def *structDef, parent *scope) (map[string]interface{}, error) {
+	if d.depth == maxDepth {
+		return nil, bytepal.ErrDepth
+	}
+	d.bits = nil
+	d.depth++
+	sc := &scope{values: map[string]interface{}{}, parent: parent}
+	err := s.decodeMembers(d, def.members, sc)
+	d.depth--
+	if err != nil {
+		return nil, fmt.Errorf("%s.%w", def.name, err)
+	}
+	d.bits = nil
+	return sc.values, nil
+}
+
+func (s *Schema) decodeMembers(d *decoder, members []member, sc *scope) error {
+	for _, m := range members {
+		switch m := m.(type) {
+		case *fieldDef:
+			path := d.path
+			if d.spans != nil {
+				d.path = join(path, m.name)
+			}
+			v, err := s.decodeField(d, m, sc)
+			d.path = path
+			if err != nil {
+				return fieldError(m.name, err)
+			}
+			sc.values[m.name] = v
+		case *ifDef:
+			cond, err := m.cond.eval(sc)
+			if err != nil {
+				return fmt.Errorf("if: %w", err)
+			}
+			branch := m.otherwise
+			if cond != 0 {
+				branch = m.then
+			}
+			if err := s.decodeMembers(d, branch, sc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) decodeField(d *decoder, f *fieldDef, sc *scope) (interface{}, error) {
+	if f.repeat == nil {
+		return s.decodeValue(d, f.typ, sc)
+	}
+	var n int
+	if f.repeat.count != nil {
+		count, err := f.repeat.count.eval(sc)
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, fmt.Errorf("negative count %d", count)
+		}
+		n = d.r.Count(uint64(count))
+	} else {
+		d.bits = nil
+		start := d.r.CurrentRead()
+		count := f.repeat.prefix.ReadInteger(d.r)
+		d.span(start, f.repeat.prefix.Key()+" prefix", count)
+		n = d.r.Count(count)
+	}
+	// The values grow as they are read, so a count the input can not back allocates nothing.
+	values := []interface{}{}
+	path := d.path
+	defer func() { d.path = path }()
+	for i := 0; i < n && d.r.Err() == nil; i++ {
+		if d.spans != nil {
+			d.path = fmt.Sprintf("%s[%d]", path, i)
+		}
+		v, err := s.decodeValue(d, f.typ, sc)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (s *Schema) decodeValue(d *decoder, t *typeDef, sc *scope) (interface{}, error) {
+	r := d.r
+	switch {
+	case t.name != "":
+		return s.decodeStruct(d, s.structs[t.name], sc)
+	case t.opcodes != nil:
+		return s.decodeOpcodes(d, t.opcodes, sc)
+	case t.tag.Type == "bits":
+		if d.bits == nil {
+			d.bits, d.bit = r.ReadBits(), r.CurrentRead()*8
+		}
+		v := uint64(d.bits(t.tag.Bits))
+		if d.spans != nil && r.Err() == nil {
+			bits := int(t.tag.Bits)
+			*d.spans = append(*d.spans, hexdump.Span{
+				Offset: d.bit / 8, Size: (d.bit%8 + bits + 7) / 8, Bit: d.bit % 8, Bits: bits,
+				Name: d.path, Type: fmt.Sprintf("bits=%d", bits), Value: v,
+			})
+		}
+		d.bit += int(t.tag.Bits)
+		return v, nil
+	}
+	d.bits = nil
+	start := r.CurrentRead()
+	v := decodePrimitive(r, t.tag)
+	d.span(start, t.tag.Key(), v)
+	return v, nil
+}
+
+func decodePrimitive(r *bytepal.Reader, tag bytepal.Tag) interface{} {
+	switch tag.Key() {
+	case "string":
+		return r.ReadString(tag.Delim)
+	case "f32":
+		return float64(r.ReadFloat32())
+	case "f32,le":
+		return float64(r.ReadLEFloat32())
+	case "f64":
+		return r.ReadFloat64()
+	case "f64,le":
+		return r.ReadLEFloat64()
+	}
+	v := tag.ReadInteger(r)
+	if tag.Signed() {
+		return int64(v)
+	}
+	return v
+}
+
+func (s *Schema) decodeOpcodes(d *decoder, def *opcodesDef, sc *scope) (interface{}, error) {
+	d.bits = nil
+	var entries []interface{}
+	path := d.path
+	defer func() { d.path = path }()
+	for r := d.r; def.until != nil || r.Remaining() > 0; {
+		start := r.CurrentRead()
+		opcode := int64(def.tag.ReadInteger(r))
+		if r.Err() != nil {
+			break
+		}
+		if def.until != nil && opcode == *def.until {
+			if d.spans != nil {
+				d.path = join(path, "until")
+				d.span(start, def.tag.Key(), opcode)
+			}
+			break
+		}
+		var index string
+		if d.spans != nil {
+			index = fmt.Sprintf("%s[%d]", path, len(entries))
+			d.path = join(index, OpcodeKey)
+			d.span(start, def.tag.Key(), opcode)
+		}
+		members, ok := def.cases[opcode]
+		if !ok {
+			return nil, fmt.Errorf("unknown opcode %d", opcode)
+		}
+		d.path = index
+		entry := &scope{values: map[string]interface{}{OpcodeKey: opcode}, parent: sc}
+		if err := s.decodeMembers(d, members, entry); err != nil {
+			return nil, fmt.Errorf("opcode %d: %w", opcode, err)
+		}
+		d.bits = nil
+		entries = append(entries, entry.values)
+	}
+	return entries, nil
+}
+
+func (s *Schema) encodeStruct(e *encoder, def *structDef, v map[string]interface{}, parent *scope) error {
+	e.bits = nil
+	if err := s.encodeMembers(e, def.members, &scope{values: v, parent: parent}); err != nil {
+		return fmt.Errorf("%s.%w", def.name, err)
+	}
+	e.bits = nil
+	return nil
+}
+
+func (s *Schema) encodeMembers(e *encoder, members []member, sc *scope) error {
+	for _, m := range members {
+		switch m := m.(type) {
+		case *fieldDef:
+			v, ok := sc.values[m.name]
+			if !ok {
+				return fieldError(m.name, fmt.Errorf("missing"))
+			}
+			if err := s.encodeField(e, m, v, sc); err != nil {
+				return fieldError(m.name, err)
+			}
+		case *ifDef:
+			cond, err := m.cond.eval(sc)
+			if err != nil {
+				return fmt.Errorf("if: %w", err)
+			}
+			branch := m.otherwise
+			if cond != 0 {
+				branch = m.then
+			}
+			if err := s.encodeMembers(e, branch, sc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) encodeField(e *encoder, f *fieldDef, v interface{}, sc *scope) error {
+	if f.repeat == nil {
+		return s.encodeValue(e, f.typ, v, sc)
+	}
+	values, err := toSlice(v)
+	if err != nil {
+		return err
+	}
+	if f.repeat.count != nil {
+		count, err := f.repeat.count.eval(sc)
+		if err != nil {
+			return err
+		}
+		if count != int64(len(values)) {
+			return fmt.Errorf("%d values for a count of %d", len(values), count)
+		}
+	} else {
+		e.bits = nil
+		if err := f.repeat.prefix.WriteInteger(e.w, uint64(len(values))); err != nil {
+			return err
+		}
+	}
+	for i, value := range values {
+		if err := s.encodeValue(e, f.typ, value, sc); err != nil {
+			return fmt.Errorf("[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s *Schema) encodeValue(e *encoder, t *typeDef, v interface{}, sc *scope) error {
+	w := e.w
+	switch {
+	case t.name != "":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%T is not a map", v)
+		}
+		return s.encodeStruct(e, s.structs[t.name], m, sc)
+	case t.opcodes != nil:
+		return s.encodeOpcodes(e, t.opcodes, v, sc)
+	case t.tag.Type == "bits":
+		i, err := toInt64(v)
+		if err != nil {
+			return err
+		}
+		if i < 0 || uint64(i)>>t.tag.Bits != 0 {
+			return fmt.Errorf("%w: %d does not fit %d bits", bytepal.ErrRange, i, t.tag.Bits)
+		}
+		if e.bits == nil {
+			e.bits = w.BitAccess()
+		}
+		e.bits(t.tag.Bits, uint(i))
+		return nil
+	}
+	e.bits = nil
+	switch t.tag.Key() {
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%T is not a string", v)
+		}
+		w.WriteString(str, t.tag.Delim)
+		return nil
+	case "f32", "f32,le", "f64", "f64,le":
+		f, err := toFloat64(v)
+		if err != nil {
+			return err
+		}
+		switch t.tag.Key() {
+		case "f32":
+			w.WriteFloat32(float32(f))
+		case "f32,le":
+			w.WriteLEFloat32(float32(f))
+		case "f64":
+			w.WriteFloat64(f)
+		default:
+			w.WriteLEFloat64(f)
+		}
+		return nil
+	}
+	i, err := toInt64(v)
+	if err != nil {
+		return err
+	}
+	if !t.tag.Signed() {
+		// Unsigned 64 bit values past math.MaxInt64 would not survive the trip through int64.
+		if u, ok := v.(uint64); ok {
+			return t.tag.WriteInteger(w, u)
+		}
+		if i < 0 {
+			return fmt.Errorf("%w: %d does not fit %s", bytepal.ErrRange, i, t.tag.Key())
+		}
+	}
+	return t.tag.WriteInteger(w, uint64(i))
+}
+
+func (s *Schema) encodeOpcodes(e *encoder, def *opcodesDef, v interface{}, sc *scope) error {
+	e.bits = nil
+	entries, err := toSlice(v)
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		values, ok := entry.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("[%d]: %T is not a map", i, entry)
+		}
+		opcode, err := toInt64(values[OpcodeKey])
+		if err != nil {
+			return fmt.Errorf("[%d]: %s: %w", i, OpcodeKey, err)
+		}
+		members, ok := def.cases[opcode]
+		if !ok {
+			return fmt.Errorf("[%d]: unknown opcode %d", i, opcode)
+		}
+		if err := def.tag.WriteInteger(e.w, uint64(opcode)); err != nil {
+			return err
+		}
+		if err := s.encodeMembers(e, members, &scope{values: values, parent: sc}); err != nil {
+			return fmt.Errorf("[%d]: opcode %d: %w", i, opcode, err)
+		}
+		e.bits = nil
+	}
+	if def.until != nil {
+		return def.tag.WriteInteger(e.w, uint64(*def.until))
+	}
+	return nil
+}
+
+// span records the bytes read since start as the value v of the current path, unless the read failed.
+func (d *decoder) span(start int, typ string, v interface{}) {
+	if d.spans != nil && d.r.Err() == nil {
+		*d.spans = append(*d.spans, hexdump.Span{
+			Offset: start, Size: d.r.CurrentRead() - start, Name: d.path, Type: typ, Value: v,
+		})
+	}
+}
+
+// join appends the field name to a path.
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// fieldError prefixes err with the field name, nested field errors are joined by dots.
+func fieldError(name string, err error) error {
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+// toSlice accepts any slice or array.
+func toSlice(v interface{}) ([]interface{}, error) {
+	if values, ok := v.([]interface{}); ok {
+		return values, nil
+	}
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%T is not a slice", v)
+	}
+	values := make([]interface{}, value.Len())
+	for i := range values {
+		values[i] = value.Index(i).Interface()
+	}
+	return values, nil
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+	}
+	i, err := toInt64(v)
+	if err != nil {
+		return math.NaN(), fmt.Errorf("%v (%T) is not a number", v, v)
+	}
+	return float64(i), nil
+}
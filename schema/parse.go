@@ -0,0 +1,314 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Pwalne/bytepal"
+)
+
+// SyntaxError reports an invalid schema.
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("schema:%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenInt
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// parser is a recursive descent parser over a single lookahead token.
+type parser struct {
+	src string
+	pos int
+	tok token
+}
+
+// Parse parses a schema, every struct referenced by a field must be declared.
+func Parse(src string) (s *Schema, err error) {
+	p := &parser{src: src}
+	defer func() {
+		if rec := recover(); rec != nil {
+			syntaxErr, ok := rec.(*SyntaxError)
+			if !ok {
+				panic(rec)
+			}
+			err = syntaxErr
+		}
+	}()
+	p.next()
+	s = &Schema{structs: map[string]*structDef{}}
+	var fields []*fieldDef
+	for p.tok.kind != tokenEOF {
+		p.expectIdent("struct")
+		start := p.tok.pos
+		def := &structDef{name: p.ident()}
+		if _, ok := s.structs[def.name]; ok {
+			p.failAt(start, "struct %s redeclared", def.name)
+		}
+		s.structs[def.name] = def
+		s.order = append(s.order, def.name)
+		def.members = p.block(&fields)
+	}
+	for _, f := range fields {
+		if f.typ.name != "" {
+			if _, ok := s.structs[f.typ.name]; !ok {
+				return nil, &SyntaxError{Line: f.line, Column: 1, Msg: fmt.Sprintf("unknown struct %s", f.typ.name)}
+			}
+		}
+	}
+	return s, nil
+}
+
+// block parses the members between braces, fields collects every field for resolving struct names.
+func (p *parser) block(fields *[]*fieldDef) []member {
+	p.expect("{")
+	var members []member
+	for !p.accept("}") {
+		members = append(members, p.member(fields))
+	}
+	return members
+}
+
+func (p *parser) member(fields *[]*fieldDef) member {
+	if p.tok.kind == tokenIdent && p.tok.text == "if" {
+		return p.ifBlock(fields)
+	}
+	start := p.tok.pos
+	f := &fieldDef{name: p.ident(), line: p.lineOf(start)}
+	p.expect(":")
+	f.typ = p.typ(fields)
+	if p.accept("[") {
+		f.repeat = p.repeat()
+	}
+	*fields = append(*fields, f)
+	return f
+}
+
+func (p *parser) ifBlock(fields *[]*fieldDef) member {
+	p.next()
+	def := &ifDef{cond: p.expr(), then: p.block(fields)}
+	if p.tok.kind == tokenIdent && p.tok.text == "else" {
+		p.next()
+		if p.tok.kind == tokenIdent && p.tok.text == "if" {
+			def.otherwise = []member{p.ifBlock(fields)}
+		} else {
+			def.otherwise = p.block(fields)
+		}
+	}
+	return def
+}
+
+// typ parses a type word, which is scanned raw so tags such as "string,delim=10" stay whole.
+func (p *parser) typ(fields *[]*fieldDef) *typeDef {
+	start := p.tok.pos
+	word := p.word()
+	if word == "opcodes" {
+		return &typeDef{opcodes: p.opcodes(fields)}
+	}
+	if tag, err := bytepal.ParseTag(word); err == nil && tag.Type != "" && tag.Count == "" {
+		return &typeDef{tag: tag}
+	} else if isIdent(word) && !strings.ContainsAny(word, ",=") {
+		return &typeDef{name: word}
+	} else if err != nil {
+		p.failAt(start, "%v", err)
+	}
+	p.failAt(start, "invalid type %q", word)
+	return nil
+}
+
+func (p *parser) opcodes(fields *[]*fieldDef) *opcodesDef {
+	start := p.tok.pos
+	def := &opcodesDef{cases: map[int64][]member{}}
+	tag, err := bytepal.ParseTag(p.word())
+	if err != nil || !tag.Integer() {
+		p.failAt(start, "opcodes need an integer encoding")
+	}
+	def.tag = tag
+	if p.tok.kind == tokenIdent && p.tok.text == "until" {
+		p.next()
+		until := p.integer()
+		def.until = &until
+	}
+	p.expect("{")
+	for !p.accept("}") {
+		start := p.tok.pos
+		opcode := p.integer()
+		if _, ok := def.cases[opcode]; ok || def.until != nil && opcode == *def.until {
+			p.failAt(start, "opcode %d declared twice", opcode)
+		}
+		def.cases[opcode] = p.block(fields)
+	}
+	return def
+}
+
+func (p *parser) repeat() *repeatDef {
+	// A count prefix is an encoding followed by "prefix", anything else is an expression.
+	if p.tok.kind == tokenIdent {
+		save, tok := p.pos, p.tok
+		word := p.word()
+		if p.tok.kind == tokenIdent && p.tok.text == "prefix" {
+			tag, err := bytepal.ParseTag(word)
+			if err != nil || !tag.Integer() || tag.Signed() {
+				p.failAt(tok.pos, "prefix needs an unsigned integer encoding")
+			}
+			p.next()
+			p.expect("]")
+			return &repeatDef{prefix: tag}
+		}
+		p.pos, p.tok = save, tok
+	}
+	def := &repeatDef{count: p.expr()}
+	p.expect("]")
+	return def
+}
+
+// word scans the raw text of the next type, up to whitespace, a bracket, a brace or a comment.
+func (p *parser) word() string {
+	start := p.tok.pos
+	end := start
+	for end < len(p.src) && !strings.ContainsRune(" \t\r\n[]{}#", rune(p.src[end])) {
+		end++
+	}
+	if end == start {
+		p.failAt(start, "expected a type")
+	}
+	p.pos = end
+	p.next()
+	return p.src[start:end]
+}
+
+func isIdent(s string) bool {
+	for i, r := range s {
+		if !(r == '_' || unicode.IsLetter(r) || i > 0 && unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func (p *parser) ident() string {
+	if p.tok.kind != tokenIdent {
+		p.fail("expected a name, found %q", p.tok.text)
+	}
+	text := p.tok.text
+	p.next()
+	return text
+}
+
+func (p *parser) integer() int64 {
+	negative := p.accept("-")
+	if p.tok.kind != tokenInt {
+		p.fail("expected an integer, found %q", p.tok.text)
+	}
+	v, err := strconv.ParseInt(p.tok.text, 0, 64)
+	if err != nil {
+		p.fail("invalid integer %q", p.tok.text)
+	}
+	p.next()
+	if negative {
+		v = -v
+	}
+	return v
+}
+
+func (p *parser) expectIdent(text string) {
+	if p.tok.kind != tokenIdent || p.tok.text != text {
+		p.fail("expected %q, found %q", text, p.tok.text)
+	}
+	p.next()
+}
+
+func (p *parser) expect(punct string) {
+	if !p.accept(punct) {
+		p.fail("expected %q, found %q", punct, p.tok.text)
+	}
+}
+
+// accept consumes the next token if it is the punctuation punct.
+func (p *parser) accept(punct string) bool {
+	if p.tok.kind == tokenPunct && p.tok.text == punct {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) fail(format string, args ...interface{}) {
+	p.failAt(p.tok.pos, format, args...)
+}
+
+func (p *parser) failAt(pos int, format string, args ...interface{}) {
+	line := p.lineOf(pos)
+	column := pos - strings.LastIndexByte(p.src[:pos], '\n')
+	panic(&SyntaxError{Line: line, Column: column, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (p *parser) lineOf(pos int) int {
+	return strings.Count(p.src[:pos], "\n") + 1
+}
+
+// Operators of two characters, checked before single characters.
+var punctuation2 = []string{"==", "!=", "<=", ">=", "&&", "||", "<<", ">>"}
+
+// next scans the next token.
+func (p *parser) next() {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+			continue
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			break
+		}
+		p.pos++
+	}
+	start := p.pos
+	if start == len(p.src) {
+		p.tok = token{kind: tokenEOF, text: "end of schema", pos: start}
+		return
+	}
+	c := rune(p.src[start])
+	switch {
+	case c == '_' || unicode.IsLetter(c):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenIdent, text: p.src[start:p.pos], pos: start}
+	case unicode.IsDigit(c):
+		for p.pos < len(p.src) && (unicode.IsDigit(rune(p.src[p.pos])) || unicode.IsLetter(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenInt, text: p.src[start:p.pos], pos: start}
+	default:
+		p.pos++
+		for _, punct := range punctuation2 {
+			if strings.HasPrefix(p.src[start:], punct) {
+				p.pos = start + 2
+				break
+			}
+		}
+		p.tok = token{kind: tokenPunct, text: p.src[start:p.pos], pos: start}
+	}
+}
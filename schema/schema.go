@@ -0,0 +1,95 @@
+// Package schema describes binary formats in a small text language and interprets them with a bytepal
+//	Reader and Writer, decoding into and encoding from generic trees of maps and slices.
+//
+//	A schema is a list of structs. Members of a struct are fields, conditions and opcode blocks:
+//
+//		# Comments run to the end of the line.
+//		struct Item {
+//			id: bigsmart
+//			amount: u8
+//			if amount == 255 {
+//				big_amount: u32,le
+//			}
+//		}
+//
+//		struct Definition {
+//			position: Position
+//			items: Item[u8 prefix]
+//			flags: bits=1[8]
+//			name: string,delim=10
+//			properties: opcodes u8 until 0 {
+//				1 { model: u16 }
+//				2 { count: u8 colors: u16[count * 2] }
+//				3 { }
+//			}
+//		}
+//
+//	Field types are the encodings of bytepal struct tags, such as u16,le or bits=5, or the name of a struct.
+//	A type may be repeated by an expression over earlier fields, T[expr], or by a count prefix of an
+//	unsigned encoding, T[u8 prefix]. Conditions take if, else if and else branches whose fields are added
+//	to the enclosing struct. Expressions support integer literals, field names (dotted for nested structs)
+//	and the Go operators for integers and booleans.
+//
+//	An opcode block reads opcodes of its encoding until the terminator, or the end of the input if
+//	"until" is left out, each decoding the members of its case. It decodes to a slice of maps holding the
+//	"opcode" and the fields of its case, so the opcodes are written back in their original order.
+//
+//	Decoded unsigned integers are uint64, signed integers int64, floats float64, bit fields uint64,
+//	strings string, structs map[string]interface{} and repeats []interface{}. Encoding accepts any Go
+//	number for integer and float fields.
+package schema
+
+import "github.com/Pwalne/bytepal"
+
+// Schema is a parsed set of structs.
+type Schema struct {
+	structs map[string]*structDef
+	// order lists the struct names in declaration order.
+	order []string
+}
+
+// Structs returns the names of the structs of the schema in declaration order.
+func (s *Schema) Structs() []string {
+	return append([]string(nil), s.order...)
+}
+
+type structDef struct {
+	name    string
+	members []member
+}
+
+// member is a *fieldDef or *ifDef.
+type member interface{}
+
+type fieldDef struct {
+	name   string
+	typ    *typeDef
+	repeat *repeatDef
+	line   int
+}
+
+// typeDef is a primitive encoding, a struct or an opcode block.
+type typeDef struct {
+	tag     bytepal.Tag
+	name    string
+	opcodes *opcodesDef
+}
+
+// repeatDef repeats a field count times or by a count prefix encoded as prefix.
+type repeatDef struct {
+	count  expr
+	prefix bytepal.Tag
+}
+
+type ifDef struct {
+	cond expr
+	then []member
+	// otherwise holds the else branch, an else if is a single nested *ifDef.
+	otherwise []member
+}
+
+type opcodesDef struct {
+	tag   bytepal.Tag
+	until *int64
+	cases map[int64][]member
+}
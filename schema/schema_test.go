@@ -0,0 +1,275 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Pwalne/bytepal"
+	"github.com/Pwalne/bytepal/hexdump"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `
+# Positions pack their coordinates into bits.
+struct Position {
+	x: bits=14
+	y: bits=14
+	plane: bits=2
+}
+
+struct Item {
+	id: bigsmart
+	amount: u8
+	if amount == 255 {
+		big_amount: u32,le
+	} else if amount == 0 {
+		reason: string,delim=10
+	}
+}
+
+struct Definition {
+	position: Position
+	items: Item[u8 prefix]
+	flags: bits=1[4]
+	name: string,delim=10
+	properties: opcodes u8 until 0 {
+		1 { model: u16 }
+		2 {
+			count: u8
+			colors: u16,le[count * 2]
+		}
+		3 { }
+		4 { scale: f32 offset: i24 }
+	}
+}
+`
+
+func TestDecode(t *testing.T) {
+	s, err := Parse(testSchema)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Position", "Item", "Definition"}, s.Structs())
+
+	w := bytepal.NewExpandableWriter()
+	bits := w.BitAccess()
+	bits(14, 3200)
+	bits(14, 3201)
+	bits(2, 1)
+	w.WriteUInt8(3)
+	w.WriteBigSmart(70000)
+	w.WriteUInt8(255)
+	w.WriteLEUInt32(100000)
+	w.WriteBigSmart(1)
+	w.WriteUInt8(0)
+	w.WriteString("gone", 10)
+	w.WriteBigSmart(2)
+	w.WriteUInt8(5)
+	bits = w.BitAccess()
+	bits(1, 1)
+	bits(1, 0)
+	bits(1, 1)
+	bits(1, 1)
+	w.WriteString("Rune platebody", 10)
+	w.WriteUInt8(2)
+	w.WriteUInt8(1)
+	w.WriteLEUInt16(10)
+	w.WriteLEUInt16(20)
+	w.WriteUInt8(1)
+	w.WriteUInt16(500)
+	w.WriteUInt8(3)
+	w.WriteUInt8(4)
+	w.WriteFloat32(1.5)
+	w.WriteMedium(-2)
+	w.WriteUInt8(0)
+
+	r := bytepal.NewReader(w.Payload())
+	v, err := s.Decode(r, "Definition")
+	require.NoError(t, err)
+	assert.Equal(t, 0, r.Remaining())
+
+	expected := map[string]interface{}{
+		"position": map[string]interface{}{"x": uint64(3200), "y": uint64(3201), "plane": uint64(1)},
+		"items": []interface{}{
+			map[string]interface{}{"id": uint64(70000), "amount": uint64(255), "big_amount": uint64(100000)},
+			map[string]interface{}{"id": uint64(1), "amount": uint64(0), "reason": "gone"},
+			map[string]interface{}{"id": uint64(2), "amount": uint64(5)},
+		},
+		"flags": []interface{}{uint64(1), uint64(0), uint64(1), uint64(1)},
+		"name":  "Rune platebody",
+		"properties": []interface{}{
+			map[string]interface{}{"opcode": int64(2), "count": uint64(1), "colors": []interface{}{uint64(10), uint64(20)}},
+			map[string]interface{}{"opcode": int64(1), "model": uint64(500)},
+			map[string]interface{}{"opcode": int64(3)},
+			map[string]interface{}{"opcode": int64(4), "scale": 1.5, "offset": int64(-2)},
+		},
+	}
+	assert.Equal(t, expected, v)
+
+	// Encoding the tree gives back the same bytes.
+	out := bytepal.NewExpandableWriter()
+	require.NoError(t, s.Encode(out, "Definition", v))
+	assert.Equal(t, w.Payload(), out.Payload())
+}
+
+func TestEncode_GoValues(t *testing.T) {
+	s, err := Parse(`struct T { kind: u8 size: u16 data: i8[size] ops: opcodes u8 { 7 { v: varint } } }`)
+	require.NoError(t, err)
+	out := bytepal.NewExpandableWriter()
+	require.NoError(t, s.Encode(out, "T", map[string]interface{}{
+		"kind": 1.0,
+		"size": 2,
+		"data": []int{-1, 1},
+		"ops":  []map[string]interface{}{{"opcode": 7, "v": -3}},
+	}))
+	assert.Equal(t, []byte{1, 0, 2, 0xFF, 1, 7, 5}, out.Payload())
+
+	v, err := s.Decode(bytepal.NewReader(out.Payload()), "T")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"opcode": int64(7), "v": int64(-3)}}, v["ops"])
+}
+
+func TestEncode_Errors(t *testing.T) {
+	s, err := Parse(`struct T { a: u8 b: bits=2 c: u16[a] }`)
+	require.NoError(t, err)
+	tests := map[string]map[string]interface{}{
+		"missing":     {"a": 0, "b": 0},
+		"range":       {"a": 256, "b": 0, "c": []int{}},
+		"negative":    {"a": -1, "b": 0, "c": []int{}},
+		"bits":        {"a": 0, "b": 4, "c": []int{}},
+		"count":       {"a": 1, "b": 0, "c": []int{}},
+		"not a slice": {"a": 1, "b": 0, "c": 1},
+		"not number":  {"a": "1", "b": 0, "c": []int{}},
+	}
+	for name, v := range tests {
+		assert.Error(t, s.Encode(bytepal.NewExpandableWriter(), "T", v), name)
+	}
+	err = s.Encode(bytepal.NewExpandableWriter(), "T", map[string]interface{}{"a": 256, "b": 0, "c": []int{}})
+	assert.True(t, errors.Is(err, bytepal.ErrRange))
+	assert.EqualError(t, err, "T.a: bytepal: value out of range: 256 does not fit u8")
+}
+
+func TestDecode_Errors(t *testing.T) {
+	s, err := Parse(`struct T { n: u8 v: u32[n] ops: opcodes u8 until 0 { 1 { } } }`)
+	require.NoError(t, err)
+
+	_, err = s.Decode(bytepal.NewReader([]byte{2, 0, 0, 0, 1}), "T")
+	assert.IsType(t, &bytepal.ReadError{}, err)
+
+	_, err = s.Decode(bytepal.NewCheckedReader([]byte{0, 1, 9}), "T")
+	assert.EqualError(t, err, "T.ops: unknown opcode 9")
+
+	_, err = s.Decode(bytepal.NewReader(nil), "U")
+	assert.Error(t, err)
+
+	// Failures other than short reads are returned by unchecked readers too.
+	s, err = Parse("struct P { a: uvarint }")
+	require.NoError(t, err)
+	malformed := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}
+	_, err = s.Decode(bytepal.NewReader(malformed), "P")
+	assert.True(t, errors.Is(err, bytepal.ErrVarintOverflow), "%v", err)
+	_, spans, err := s.DecodeSpans(bytepal.NewReader(malformed), "P")
+	assert.True(t, errors.Is(err, bytepal.ErrVarintOverflow), "%v", err)
+	assert.Empty(t, spans)
+}
+
+func TestDecode_Depth(t *testing.T) {
+	s, err := Parse("struct Node { value: u8 children: Node[u8 prefix] }\nstruct Loop { next: Loop }")
+	require.NoError(t, err)
+
+	// Every node holds one child, nesting them past the limit.
+	var data []byte
+	for i := 0; i < 300; i++ {
+		data = append(data, 7, 1)
+	}
+	_, err = s.Decode(bytepal.NewCheckedReader(data), "Node")
+	assert.True(t, errors.Is(err, bytepal.ErrDepth), "%v", err)
+
+	v, err := s.Decode(bytepal.NewCheckedReader([]byte{7, 1, 8, 0}), "Node")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"value": uint64(7), "children": []interface{}{
+		map[string]interface{}{"value": uint64(8), "children": []interface{}{}},
+	}}, v)
+
+	// A struct holding itself reads nothing and would recurse forever.
+	_, _, err = s.DecodeSpans(bytepal.NewReader(nil), "Loop")
+	assert.True(t, errors.Is(err, bytepal.ErrDepth), "%v", err)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"struct T { a: u8,le }":                      "schema:1:15:",
+		"struct T {\n a: Missing\n}":                 "schema:2:1: unknown struct Missing",
+		"struct T { a u8 }":                          "schema:1:14:",
+		"struct T { a: u8[u16 prefix }":              "schema:1:29:",
+		"struct T { a: i8[i8 prefix] }":              "schema:1:18: prefix needs",
+		"struct T { a: opcodes string { } }":         "schema:1:23: opcodes need",
+		"struct T { a: opcodes u8 { 1 { } 1 { } } }": "schema:1:34: opcode 1 declared twice",
+		"struct T { if a == { } }":                   "schema:1:20: expected an expression",
+		"struct T { }\nstruct T { }":                 "schema:2:8: struct T redeclared",
+		"structure T { }":                            "schema:1:1:",
+		"struct T { a: count=u8 }":                   "schema:1:15: invalid type",
+	}
+	for src, prefix := range tests {
+		_, err := Parse(src)
+		require.Error(t, err, src)
+		assert.Contains(t, err.Error(), prefix, src)
+		assert.IsType(t, &SyntaxError{}, err, src)
+	}
+}
+
+func TestExpr(t *testing.T) {
+	tests := map[string]int64{
+		"1 + 2 * 3":          7,
+		"(1 + 2) * 3":        9,
+		"a.b << 2 | 1":       21,
+		"-a.b + 10 / 3 % 2":  -4,
+		"!(c > 2) || c == 3": 1,
+		"c >= 3 && c != 3":   0,
+		"^0 & 0xF":           15,
+		"c - 1 - 1":          1,
+	}
+	sc := &scope{values: map[string]interface{}{"a": map[string]interface{}{"b": uint64(5)}, "c": int64(3)}}
+	for src, expected := range tests {
+		p := &parser{src: src}
+		p.next()
+		v, err := p.expr().eval(sc)
+		require.NoError(t, err, src)
+		assert.Equal(t, expected, v, src)
+	}
+	p := &parser{src: "1 / (c - 3)"}
+	p.next()
+	_, err := p.expr().eval(sc)
+	assert.Error(t, err)
+}
+
+func TestDecodeSpans(t *testing.T) {
+	s, err := Parse(`
+struct Flag { on: bits=1 }
+struct T {
+	a: bits=3
+	b: bits=7
+	flags: Flag[u8 prefix]
+	name: string
+	ops: opcodes u8 until 0 { 1 { v: u16 } }
+}`)
+	require.NoError(t, err)
+	data := []byte{0xFF, 0xFF, 2, 0x80, 0x00, 'h', 'i', 0, 1, 0, 5, 0, 9, 9}
+	_, spans, err := s.DecodeSpans(bytepal.NewReader(data), "T")
+	require.NoError(t, err)
+	assert.Equal(t, []hexdump.Span{
+		{Offset: 0, Size: 1, Bit: 0, Bits: 3, Name: "a", Type: "bits=3", Value: uint64(7)},
+		{Offset: 0, Size: 2, Bit: 3, Bits: 7, Name: "b", Type: "bits=7", Value: uint64(127)},
+		{Offset: 2, Size: 1, Name: "flags", Type: "u8 prefix", Value: uint64(2)},
+		{Offset: 3, Size: 1, Bits: 1, Name: "flags[0].on", Type: "bits=1", Value: uint64(1)},
+		{Offset: 4, Size: 1, Bits: 1, Name: "flags[1].on", Type: "bits=1", Value: uint64(0)},
+		{Offset: 5, Size: 3, Name: "name", Type: "string", Value: "hi"},
+		{Offset: 8, Size: 1, Name: "ops[0].opcode", Type: "u8", Value: int64(1)},
+		{Offset: 9, Size: 2, Name: "ops[0].v", Type: "u16", Value: uint64(5)},
+		{Offset: 11, Size: 1, Name: "ops.until", Type: "u8", Value: int64(0)},
+	}, spans)
+
+	// The spans read before a failure are kept.
+	_, spans, err = s.DecodeSpans(bytepal.NewReader(data[:9]), "T")
+	assert.IsType(t, &bytepal.ReadError{}, err)
+	assert.Len(t, spans, 7)
+}
@@ -0,0 +1,181 @@
+package bytepal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrRange is recorded when a value can not be represented by the encoding it is written with.
+var ErrRange = errors.New("bytepal: value out of range")
+
+// ReadSmart reads a value from 0 to 32767 stored in a byte if it is below 128, otherwise in a short.
+func (b *Reader) ReadSmart() uint16 {
+	if b.peek() < 128 {
+		return uint16(b.ReadUInt8())
+	}
+	return binary.BigEndian.Uint16(b.take(2)) - 32768
+}
+
+// ReadSignedSmart reads a value from -16384 to 16383, values from -64 to 63 are stored in a single byte.
+func (b *Reader) ReadSignedSmart() int16 {
+	if b.peek() < 128 {
+		return int16(b.ReadUInt8()) - 64
+	}
+	return int16(binary.BigEndian.Uint16(b.take(2)) - 49152)
+}
+
+// ReadBigSmartValue reads a big smart and clears the flag bit of the int form, so it returns the value
+//	passed to WriteBigSmart.
+func (b *Reader) ReadBigSmartValue() uint32 {
+	return b.ReadBigSmart() & math.MaxInt32
+}
+
+// ReadNullableBigSmart reads a big smart where the short value 32767 represents -1.
+func (b *Reader) ReadNullableBigSmart() int32 {
+	if int8(b.peek()) < 0 {
+		return int32(binary.BigEndian.Uint32(b.take(4)) & math.MaxInt32)
+	}
+	if v := binary.BigEndian.Uint16(b.take(2)); v != math.MaxInt16 {
+		return int32(v)
+	}
+	return -1
+}
+
+// ReadIncrSmart reads a sequence of smarts, adding up each 32767 until a smaller value ends the sequence.
+func (b *Reader) ReadIncrSmart() int {
+	total := 0
+	v := b.ReadSmart()
+	for ; v == math.MaxInt16 && b.err == nil; v = b.ReadSmart() {
+		total += math.MaxInt16
+	}
+	return total + int(v)
+}
+
+// reserver is implemented by the writers of this package, reserve returns the next size bytes to be written.
+//	Multi byte smarts reserve all of their bytes at once, so an overflow policy never keeps half of one.
+type reserver interface {
+	reserve(size int) []byte
+}
+
+func writeSmart(w reserver, v uint16) error {
+	switch {
+	case v < 128:
+		w.reserve(1)[0] = uint8(v)
+	case v <= math.MaxInt16:
+		binary.BigEndian.PutUint16(w.reserve(2), v+32768)
+	default:
+		return fmt.Errorf("%w: smart %d", ErrRange, v)
+	}
+	return nil
+}
+
+func writeSignedSmart(w reserver, v int16) error {
+	switch {
+	case v >= -64 && v < 64:
+		w.reserve(1)[0] = uint8(v + 64)
+	case v >= -16384 && v < 16384:
+		binary.BigEndian.PutUint16(w.reserve(2), uint16(v)+49152)
+	default:
+		return fmt.Errorf("%w: signed smart %d", ErrRange, v)
+	}
+	return nil
+}
+
+func writeBigSmart(w reserver, v uint32) error {
+	switch {
+	case v <= math.MaxInt16:
+		binary.BigEndian.PutUint16(w.reserve(2), uint16(v))
+	case v <= math.MaxInt32:
+		binary.BigEndian.PutUint32(w.reserve(4), v|1<<31)
+	default:
+		return fmt.Errorf("%w: big smart %d", ErrRange, v)
+	}
+	return nil
+}
+
+func writeNullableBigSmart(w reserver, v int32) error {
+	switch {
+	case v == -1:
+		binary.BigEndian.PutUint16(w.reserve(2), math.MaxInt16)
+	case v >= 0 && v < math.MaxInt16:
+		binary.BigEndian.PutUint16(w.reserve(2), uint16(v))
+	case v >= math.MaxInt16:
+		binary.BigEndian.PutUint32(w.reserve(4), uint32(v)|1<<31)
+	default:
+		return fmt.Errorf("%w: nullable big smart %d", ErrRange, v)
+	}
+	return nil
+}
+
+func writeIncrSmart(w reserver, v int) error {
+	if v < 0 {
+		return fmt.Errorf("%w: incremental smart %d", ErrRange, v)
+	}
+	count, last := v/math.MaxInt16, uint16(v%math.MaxInt16)
+	size := count*2 + 1
+	if last >= 128 {
+		size++
+	}
+	data := w.reserve(size)
+	for i := 0; i < count; i++ {
+		binary.BigEndian.PutUint16(data[i*2:], math.MaxInt16+32768)
+	}
+	if last < 128 {
+		data[size-1] = uint8(last)
+	} else {
+		binary.BigEndian.PutUint16(data[size-2:], last+32768)
+	}
+	return nil
+}
+
+// WriteSmart writes a value from 0 to 32767 as a byte if it is below 128, otherwise as a short.
+func (a *FixedWriter) WriteSmart(v uint16) {
+	a.fail(writeSmart(a, v))
+}
+
+// WriteSignedSmart writes a value from -16384 to 16383, values from -64 to 63 take a single byte.
+func (a *FixedWriter) WriteSignedSmart(v int16) {
+	a.fail(writeSignedSmart(a, v))
+}
+
+// WriteBigSmart writes v as a short if it is below 32768, otherwise as an int with the flag bit set.
+func (a *FixedWriter) WriteBigSmart(v uint32) {
+	a.fail(writeBigSmart(a, v))
+}
+
+// WriteNullableBigSmart writes a big smart where -1 is stored as the short value 32767.
+func (a *FixedWriter) WriteNullableBigSmart(v int32) {
+	a.fail(writeNullableBigSmart(a, v))
+}
+
+// WriteIncrSmart writes v as a sequence of smarts, each 32767 is written separately until the remainder fits.
+func (a *FixedWriter) WriteIncrSmart(v int) {
+	a.fail(writeIncrSmart(a, v))
+}
+
+// WriteSmart writes a value from 0 to 32767 as a byte if it is below 128, otherwise as a short.
+func (a *ExpandableWriter) WriteSmart(v uint16) {
+	a.fail(writeSmart(a, v))
+}
+
+// WriteSignedSmart writes a value from -16384 to 16383, values from -64 to 63 take a single byte.
+func (a *ExpandableWriter) WriteSignedSmart(v int16) {
+	a.fail(writeSignedSmart(a, v))
+}
+
+// WriteBigSmart writes v as a short if it is below 32768, otherwise as an int with the flag bit set.
+func (a *ExpandableWriter) WriteBigSmart(v uint32) {
+	a.fail(writeBigSmart(a, v))
+}
+
+// WriteNullableBigSmart writes a big smart where -1 is stored as the short value 32767.
+func (a *ExpandableWriter) WriteNullableBigSmart(v int32) {
+	a.fail(writeNullableBigSmart(a, v))
+}
+
+// WriteIncrSmart writes v as a sequence of smarts, each 32767 is written separately until the remainder fits.
+func (a *ExpandableWriter) WriteIncrSmart(v int) {
+	a.fail(writeIncrSmart(a, v))
+}
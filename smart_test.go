@@ -0,0 +1,122 @@
+package bytepal
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testSmarts(t *testing.T, out Writer) {
+	smarts := []uint16{0, 127, 128, 32767}
+	signedSmarts := []int16{-64, 63, -65, 64, -16384, 16383}
+	bigSmarts := []uint32{0, 32767, 32768, 2147483647}
+	nullableBigSmarts := []int32{-1, 0, 32766, 32767, 2147483647}
+	incrSmarts := []int{0, 32766, 32767, 32768, 100000}
+
+	for _, v := range smarts {
+		out.WriteSmart(v)
+	}
+	for _, v := range signedSmarts {
+		out.WriteSignedSmart(v)
+	}
+	for _, v := range bigSmarts {
+		out.WriteBigSmart(v)
+	}
+	for _, v := range nullableBigSmarts {
+		out.WriteNullableBigSmart(v)
+	}
+	for _, v := range incrSmarts {
+		out.WriteIncrSmart(v)
+	}
+	require.NoError(t, out.Err())
+
+	in := NewCheckedReader(out.Payload())
+	for _, v := range smarts {
+		assert.Equal(t, v, in.ReadSmart())
+	}
+	for _, v := range signedSmarts {
+		assert.Equal(t, v, in.ReadSignedSmart())
+	}
+	for _, v := range bigSmarts {
+		assert.Equal(t, v, in.ReadBigSmartValue())
+	}
+	for _, v := range nullableBigSmarts {
+		assert.Equal(t, v, in.ReadNullableBigSmart())
+	}
+	for _, v := range incrSmarts {
+		assert.Equal(t, v, in.ReadIncrSmart())
+	}
+	assert.NoError(t, in.Err())
+	assert.Equal(t, 0, in.Remaining())
+}
+
+func TestFixedWriter_Smarts(t *testing.T) {
+	testSmarts(t, NewFixedWriterWithPolicy(0, OverflowGrow))
+}
+func TestExpandableWriter_Smarts(t *testing.T) {
+	testSmarts(t, NewExpandableWriter())
+}
+
+func TestWriter_SmartEncodings(t *testing.T) {
+	out := NewExpandableWriter()
+	out.WriteSmart(127)
+	out.WriteSmart(128)
+	out.WriteSignedSmart(-1)
+	out.WriteSignedSmart(100)
+	out.WriteBigSmart(32768)
+	out.WriteNullableBigSmart(-1)
+	out.WriteIncrSmart(32767)
+	assert.Equal(t, []byte{
+		0x7F,
+		0x80, 0x80,
+		0x3F,
+		0xC0, 0x64,
+		0x80, 0x00, 0x80, 0x00,
+		0x7F, 0xFF,
+		0xFF, 0xFF, 0x00,
+	}, out.Payload())
+}
+
+func TestWriter_SmartRange(t *testing.T) {
+	writes := map[string]func(w Writer){
+		"WriteSmart":            func(w Writer) { w.WriteSmart(32768) },
+		"WriteSignedSmart":      func(w Writer) { w.WriteSignedSmart(16384) },
+		"WriteBigSmart":         func(w Writer) { w.WriteBigSmart(1 << 31) },
+		"WriteNullableBigSmart": func(w Writer) { w.WriteNullableBigSmart(-2) },
+		"WriteIncrSmart":        func(w Writer) { w.WriteIncrSmart(-1) },
+	}
+	for name, write := range writes {
+		out := NewExpandableWriter()
+		write(out)
+		assert.True(t, errors.Is(out.Err(), ErrRange), name)
+		assert.Empty(t, out.Payload(), name)
+	}
+}
+
+func TestFixedWriter_SmartOverflow(t *testing.T) {
+	// A smart that does not fit is dropped as a whole, never leaving its first bytes behind.
+	out := NewFixedWriterWithPolicy(3, OverflowDrop)
+	out.WriteUInt16(0x0102)
+	out.WriteSmart(300)
+	out.WriteBigSmart(70000)
+	out.WriteIncrSmart(32767)
+	out.WriteSmart(5)
+	assert.NoError(t, out.Err())
+	assert.Equal(t, []byte{1, 2, 5}, out.Payload())
+
+	out = NewFixedWriterWithPolicy(5, OverflowError)
+	out.WriteUInt16(0x0102)
+	out.WriteBigSmart(70000)
+	assert.Equal(t, &WriteError{Offset: 2, Size: 4, Available: 3}, out.Err())
+	assert.Equal(t, []byte{1, 2, 0, 0, 0}, out.Payload())
+}
+
+func TestReader_ReadBigSmart(t *testing.T) {
+	reader := NewReader([]byte{0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	assert.Equal(t, uint32(32767), reader.ReadBigSmart())
+	assert.Equal(t, uint32(0xFFFFFFFF), reader.ReadBigSmart())
+
+	reader.SetCurrentRead(2)
+	assert.Equal(t, uint32(2147483647), reader.ReadBigSmartValue())
+}
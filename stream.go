@@ -0,0 +1,421 @@
+package bytepal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// ErrLengthPending is returned by Flush while a length placeholder is not finished.
+var ErrLengthPending = errors.New("bytepal: flush with an unfinished length placeholder")
+
+const defaultStreamSize = 4096
+
+// StreamReader decodes values from an io.Reader through a refillable buffer, so the whole input never
+//	has to be in memory. Errors are sticky: once a read fails every read after it returns zero values.
+type StreamReader struct {
+	source io.Reader
+	// reader decodes the buffered window, its index is the current position.
+	reader *Reader
+	// offset is the position in the stream of the first buffered byte.
+	offset int64
+}
+
+// NewStreamReader creates a big endian StreamReader with a default buffer size.
+func NewStreamReader(source io.Reader) *StreamReader {
+	return NewStreamReaderWithOrder(source, defaultStreamSize, binary.BigEndian)
+}
+
+// NewStreamReaderWithOrder creates a StreamReader with an initial buffer of size bytes where ReadUInt16,
+//	ReadUInt32, ReadUInt64 and their signed and float variants follow the given byte order.
+//	The buffer grows when a single value does not fit it.
+func NewStreamReaderWithOrder(source io.Reader, size int, order binary.ByteOrder) *StreamReader {
+	reader := NewReaderWithOrder(make([]byte, 0, size), order)
+	reader.checked = true
+	return &StreamReader{
+		source: source,
+		reader: reader,
+	}
+}
+
+// Err returns the first error encountered while reading. io.EOF is returned if the stream ended
+//	before a read started and a ReadError if it ended within one, other errors come from the source.
+func (s *StreamReader) Err() error {
+	return s.reader.err
+}
+
+// Offset returns the number of bytes read off the stream.
+func (s *StreamReader) Offset() int64 {
+	return s.offset + int64(s.reader.currentIndex)
+}
+
+// Buffered returns the number of bytes that can be read without reading from the source.
+func (s *StreamReader) Buffered() int {
+	return s.reader.Remaining()
+}
+
+// fill makes sure at least n bytes are buffered, reading from the source as needed.
+func (s *StreamReader) fill(n int) bool {
+	r := s.reader
+	if r.err != nil {
+		return false
+	}
+	buffered := r.Remaining()
+	if buffered >= n {
+		return true
+	}
+
+	buf := r.bytes[:cap(r.bytes)]
+	if n > len(buf) {
+		buf = make([]byte, n+len(buf))
+	}
+	copy(buf, r.bytes[r.currentIndex:])
+	s.offset += int64(r.currentIndex)
+	read, err := io.ReadAtLeast(s.source, buf[buffered:], n-buffered)
+	r.bytes, r.currentIndex = buf[:buffered+read], 0
+	r.limit = len(r.bytes)
+	switch {
+	case err == nil:
+		return true
+	case err == io.EOF && buffered == 0:
+		r.err = io.EOF
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		r.err = &ReadError{Offset: int(s.offset), Size: n, Available: buffered + read}
+	default:
+		r.err = err
+	}
+	return false
+}
+
+// ReadUInt8 reads a single byte
+func (s *StreamReader) ReadUInt8() uint8 {
+	s.fill(1)
+	return s.reader.ReadUInt8()
+}
+
+// ReadInt8 reads a single signed byte
+func (s *StreamReader) ReadInt8() int8 {
+	s.fill(1)
+	return s.reader.ReadInt8()
+}
+
+// ReadUInt16 reads a short in the byte order of the StreamReader
+func (s *StreamReader) ReadUInt16() uint16 {
+	s.fill(2)
+	return s.reader.ReadUInt16()
+}
+
+// ReadInt16 reads a signed short in the byte order of the StreamReader
+func (s *StreamReader) ReadInt16() int16 {
+	s.fill(2)
+	return s.reader.ReadInt16()
+}
+
+// ReadLEUInt16 reads a short in little endian order
+func (s *StreamReader) ReadLEUInt16() uint16 {
+	s.fill(2)
+	return s.reader.ReadLEUInt16()
+}
+
+// ReadLEInt16 reads a signed short in little endian order
+func (s *StreamReader) ReadLEInt16() int16 {
+	s.fill(2)
+	return s.reader.ReadLEInt16()
+}
+
+// ReadUMedium reads a 24bit unsigned value
+func (s *StreamReader) ReadUMedium() uint32 {
+	s.fill(3)
+	return s.reader.ReadUMedium()
+}
+
+// ReadMedium reads a 24bit signed value
+func (s *StreamReader) ReadMedium() int32 {
+	s.fill(3)
+	return s.reader.ReadMedium()
+}
+
+// ReadUInt32 reads an int in the byte order of the StreamReader
+func (s *StreamReader) ReadUInt32() uint32 {
+	s.fill(4)
+	return s.reader.ReadUInt32()
+}
+
+// ReadInt32 reads a signed int in the byte order of the StreamReader
+func (s *StreamReader) ReadInt32() int32 {
+	s.fill(4)
+	return s.reader.ReadInt32()
+}
+
+// ReadLEUInt32 reads an int in little endian order
+func (s *StreamReader) ReadLEUInt32() uint32 {
+	s.fill(4)
+	return s.reader.ReadLEUInt32()
+}
+
+// ReadLEInt32 reads a signed int in little endian order
+func (s *StreamReader) ReadLEInt32() int32 {
+	s.fill(4)
+	return s.reader.ReadLEInt32()
+}
+
+// ReadUInt64 reads a long in the byte order of the StreamReader
+func (s *StreamReader) ReadUInt64() uint64 {
+	s.fill(8)
+	return s.reader.ReadUInt64()
+}
+
+// ReadInt64 reads a signed long in the byte order of the StreamReader
+func (s *StreamReader) ReadInt64() int64 {
+	s.fill(8)
+	return s.reader.ReadInt64()
+}
+
+// ReadLEUInt64 reads a long in little endian order
+func (s *StreamReader) ReadLEUInt64() uint64 {
+	s.fill(8)
+	return s.reader.ReadLEUInt64()
+}
+
+// ReadLEInt64 reads a signed long in little endian order
+func (s *StreamReader) ReadLEInt64() int64 {
+	s.fill(8)
+	return s.reader.ReadLEInt64()
+}
+
+// ReadFloat32 reads an IEEE-754 single precision float in the byte order of the StreamReader
+func (s *StreamReader) ReadFloat32() float32 {
+	s.fill(4)
+	return s.reader.ReadFloat32()
+}
+
+// ReadFloat64 reads an IEEE-754 double precision float in the byte order of the StreamReader
+func (s *StreamReader) ReadFloat64() float64 {
+	s.fill(8)
+	return s.reader.ReadFloat64()
+}
+
+// ReadSmart reads a value from 0 to 32767 stored in a byte if it is below 128, otherwise in a short.
+func (s *StreamReader) ReadSmart() uint16 {
+	if s.fill(1) && s.reader.peek() >= 128 {
+		s.fill(2)
+	}
+	return s.reader.ReadSmart()
+}
+
+// ReadBigSmart attempts to read either a short or int based on the next value.
+func (s *StreamReader) ReadBigSmart() uint32 {
+	if s.fill(1) && int8(s.reader.peek()) < 0 {
+		s.fill(4)
+	} else {
+		s.fill(2)
+	}
+	return s.reader.ReadBigSmart()
+}
+
+// ReadBigSmartValue reads a big smart and clears the flag bit of the int form.
+func (s *StreamReader) ReadBigSmartValue() uint32 {
+	return s.ReadBigSmart() & math.MaxInt32
+}
+
+// ReadUVarint reads an unsigned LEB128 (protobuf) varint of up to 64 bits.
+func (s *StreamReader) ReadUVarint() uint64 {
+	for n := 1; n <= MaxVarintLen64 && s.fill(n); n++ {
+		if s.reader.bytes[s.reader.currentIndex+n-1] < 0x80 {
+			break
+		}
+	}
+	return s.reader.ReadUVarint()
+}
+
+// ReadVarint reads a zigzag encoded signed varint of up to 64 bits.
+func (s *StreamReader) ReadVarint() int64 {
+	v := s.ReadUVarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// ReadString continuously reads bytes until the delimiter is read, the delimiter is not returned.
+func (s *StreamReader) ReadString(delim byte) string {
+	for searched := 0; s.fill(searched + 1); {
+		window := s.reader.bytes[s.reader.currentIndex:]
+		if bytes.IndexByte(window[searched:], delim) >= 0 {
+			break
+		}
+		searched = len(window)
+	}
+	return s.reader.ReadString(delim)
+}
+
+// ReadBytes fills payload with the next bytes of the stream, bytes that are not buffered are read
+//	straight into payload.
+func (s *StreamReader) ReadBytes(payload []byte) {
+	r := s.reader
+	if r.err != nil {
+		return
+	}
+	n := copy(payload, r.bytes[r.currentIndex:])
+	r.currentIndex += n
+	if n == len(payload) {
+		return
+	}
+	read, err := io.ReadFull(s.source, payload[n:])
+	s.offset += int64(read)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = &ReadError{Offset: int(s.Offset()) - n - read, Size: len(payload), Available: n + read}
+		}
+		r.err = err
+	}
+}
+
+// ReadSlice reads the given number of bytes into a new slice.
+func (s *StreamReader) ReadSlice(size int) []byte {
+	data := make([]byte, size)
+	s.ReadBytes(data)
+	if s.reader.err != nil {
+		return nil
+	}
+	return data
+}
+
+// Skip discards the next n bytes.
+func (s *StreamReader) Skip(n int) {
+	for n > 0 && s.fill(1) {
+		skip := s.reader.Remaining()
+		if skip > n {
+			skip = n
+		}
+		s.reader.currentIndex += skip
+		n -= skip
+	}
+}
+
+// StreamWriter is an ExpandableWriter that flushes its buffer to an io.Writer once a write would take
+//	it past its threshold. Nothing is flushed while a length placeholder is outstanding, and
+//	offsets into Payload and of BitAccess are relative to the unflushed buffer.
+//	The first error of the sink is sticky, see Err, and the buffer is discarded from then on.
+type StreamWriter struct {
+	*ExpandableWriter
+	sink      io.Writer
+	threshold int
+	marks     int
+	flushed   int64
+	// offset counts the bytes moved out of the buffer, flushed or discarded.
+	offset int
+}
+
+var _ Writer = &StreamWriter{}
+var _ io.ByteWriter = &StreamWriter{}
+
+// NewStreamWriter creates a big endian StreamWriter with a default threshold.
+func NewStreamWriter(sink io.Writer) *StreamWriter {
+	return NewStreamWriterWithOrder(sink, defaultStreamSize, binary.BigEndian)
+}
+
+// NewStreamWriterWithOrder creates a StreamWriter that buffers up to threshold bytes where WriteInt16,
+//	WriteInt32, WriteInt64 and their unsigned and float variants follow the given byte order.
+func NewStreamWriterWithOrder(sink io.Writer, threshold int, order binary.ByteOrder) *StreamWriter {
+	s := &StreamWriter{
+		ExpandableWriter: &ExpandableWriter{
+			bitWriter: &bitWriter{
+				bytes:     make([]byte, 0, threshold),
+				order:     order,
+				bigEndian: order == binary.BigEndian,
+			},
+		},
+		sink:      sink,
+		threshold: threshold,
+	}
+	s.flush = s.beforeReserve
+	return s
+}
+
+// beforeReserve flushes the buffer when size more bytes would take it past the threshold.
+func (s *StreamWriter) beforeReserve(size int) {
+	if s.err != nil {
+		s.offset += len(s.bytes)
+		s.bytes, s.currentIndex = s.bytes[:0], 0
+		return
+	}
+	if s.marks == 0 && len(s.bytes) > 0 && len(s.bytes)+size > s.threshold {
+		_ = s.Flush()
+	}
+}
+
+// Flush writes the buffered bytes to the sink.
+func (s *StreamWriter) Flush() error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.marks > 0 {
+		return ErrLengthPending
+	}
+	if len(s.bytes) == 0 {
+		return nil
+	}
+	n, err := s.sink.Write(s.bytes)
+	s.flushed += int64(n)
+	if err == nil && n < len(s.bytes) {
+		err = io.ErrShortWrite
+	}
+	s.offset += len(s.bytes)
+	s.bytes, s.currentIndex = s.bytes[:0], 0
+	s.fail(err)
+	return err
+}
+
+// Flushed returns the number of bytes written to the sink.
+func (s *StreamWriter) Flushed() int64 {
+	return s.flushed
+}
+
+// position is the offset in the stream of the next byte written.
+func (s *StreamWriter) position() int {
+	return s.offset + s.currentIndex
+}
+
+// Write adds all the bytes to the payload, writes of at least the threshold go straight to the sink.
+func (s *StreamWriter) Write(v []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.marks > 0 || len(v) < s.threshold {
+		return s.ExpandableWriter.Write(v)
+	}
+	if err := s.Flush(); err != nil {
+		return 0, err
+	}
+	n, err := s.sink.Write(v)
+	s.flushed += int64(n)
+	s.offset += len(v)
+	s.fail(err)
+	return n, err
+}
+
+// WriteByte writes a single byte, it returns the error of the sink if a flush failed.
+func (s *StreamWriter) WriteByte(c byte) error {
+	s.WriteUInt8(c)
+	return s.err
+}
+
+// ReserveLength writes a placeholder of 1, 2 or 4 bytes, or LengthVarint, to be filled in by FinishLength.
+//	The buffer is not flushed until every placeholder is finished.
+func (s *StreamWriter) ReserveLength(size int) LengthMark {
+	// The placeholder must not be flushed while it is written.
+	s.marks++
+	mark := s.ExpandableWriter.ReserveLength(size)
+	if mark.size == 0 {
+		s.marks--
+	}
+	return mark
+}
+
+// FinishLength fills in the placeholder of mark with the number of bytes written since it was reserved.
+func (s *StreamWriter) FinishLength(mark LengthMark) {
+	s.ExpandableWriter.FinishLength(mark)
+	if mark.size != 0 {
+		s.marks--
+	}
+}
@@ -0,0 +1,209 @@
+package bytepal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStreamReader_OneByte(t *testing.T) {
+	w := NewExpandableWriter()
+	w.WriteUInt8(0x7F)
+	w.WriteUInt16(0x1234)
+	w.WriteLEUInt32(0xCAFEBABE)
+	w.WriteMedium(0x10203)
+	w.WriteUInt64(0x0102030405060708)
+	w.WriteSmart(0x1234)
+	w.WriteBigSmart(0x12345)
+	w.WriteUVarint(300)
+	w.WriteVarint(-3)
+	w.WriteString(TestString, Delim)
+	w.WriteString("", Delim)
+	w.Write([]byte{1, 2, 3, 4, 5})
+
+	// A tiny buffer reading one byte at a time forces every value to cross a refill.
+	s := NewStreamReaderWithOrder(iotest.OneByteReader(bytes.NewReader(w.Payload())), 2, w.Order())
+	assert.Equal(t, uint8(0x7F), s.ReadUInt8())
+	assert.Equal(t, uint16(0x1234), s.ReadUInt16())
+	assert.Equal(t, uint32(0xCAFEBABE), s.ReadLEUInt32())
+	assert.Equal(t, uint32(0x10203), s.ReadUMedium())
+	assert.Equal(t, uint64(0x0102030405060708), s.ReadUInt64())
+	assert.Equal(t, uint16(0x1234), s.ReadSmart())
+	assert.Equal(t, uint32(0x12345), s.ReadBigSmartValue())
+	assert.Equal(t, uint64(300), s.ReadUVarint())
+	assert.Equal(t, int64(-3), s.ReadVarint())
+	assert.Equal(t, TestString, s.ReadString(Delim))
+	assert.Equal(t, "", s.ReadString(Delim))
+	s.Skip(1)
+	assert.Equal(t, []byte{2, 3, 4}, s.ReadSlice(3))
+	assert.Equal(t, int64(w.Size()-1), s.Offset())
+	require.NoError(t, s.Err())
+
+	assert.Equal(t, uint8(5), s.ReadUInt8())
+	assert.Equal(t, uint8(0), s.ReadUInt8())
+	assert.Equal(t, io.EOF, s.Err())
+}
+
+func TestStreamReader_ReadBytesLarge(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	s := NewStreamReaderWithOrder(bytes.NewReader(data), 16, binary.BigEndian)
+	assert.Equal(t, uint8(0), s.ReadUInt8())
+	payload := make([]byte, 998)
+	s.ReadBytes(payload)
+	assert.Equal(t, data[1:999], payload)
+	assert.Equal(t, int64(999), s.Offset())
+	assert.Equal(t, uint8(0xE7), s.ReadUInt8())
+	require.NoError(t, s.Err())
+}
+
+func TestStreamReader_Truncated(t *testing.T) {
+	s := NewStreamReader(bytes.NewReader([]byte{0x1, 0x2, 0x3}))
+	assert.Equal(t, uint16(0x102), s.ReadUInt16())
+	assert.Equal(t, uint32(0), s.ReadUInt32())
+	assert.True(t, errors.Is(s.Err(), io.ErrUnexpectedEOF))
+
+	var readErr *ReadError
+	require.True(t, errors.As(s.Err(), &readErr))
+	assert.Equal(t, 2, readErr.Offset)
+	assert.Equal(t, 4, readErr.Size)
+	assert.Equal(t, 1, readErr.Available)
+
+	assert.Equal(t, uint8(0), s.ReadUInt8())
+	assert.Equal(t, readErr, s.Err())
+}
+
+func TestStreamReader_SourceError(t *testing.T) {
+	broken := errors.New("broken pipe")
+	s := NewStreamReader(iotest.TimeoutReader(iotest.OneByteReader(bytes.NewReader([]byte{0x1, 0x2}))))
+	assert.Equal(t, uint16(0), s.ReadUInt16())
+	assert.Equal(t, iotest.ErrTimeout, s.Err())
+
+	s = NewStreamReader(io.MultiReader(bytes.NewReader([]byte{0x1}), iotest.ErrReader(broken)))
+	s.ReadUInt16()
+	assert.Equal(t, broken, s.Err())
+}
+
+func TestReadBytes_Full(t *testing.T) {
+	reader, err := ReadBytes(iotest.OneByteReader(bytes.NewReader([]byte{0x1, 0x2, 0x3})), 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reader.Remaining())
+
+	_, err = ReadBytes(bytes.NewReader([]byte{0x1}), 3)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+// chunkSink records the size of every write it receives.
+type chunkSink struct {
+	bytes.Buffer
+	writes []int
+}
+
+func (c *chunkSink) Write(p []byte) (int, error) {
+	c.writes = append(c.writes, len(p))
+	return c.Buffer.Write(p)
+}
+
+func TestStreamWriter_Threshold(t *testing.T) {
+	sink := &chunkSink{}
+	s := NewStreamWriterWithOrder(sink, 8, binary.BigEndian)
+	s.WriteUInt32(0x01020304)
+	s.WriteUInt32(0x05060708)
+	assert.Empty(t, sink.writes)
+	s.WriteUInt16(0x090A)
+	assert.Equal(t, []int{8}, sink.writes)
+	assert.Equal(t, 2, s.Size())
+
+	n, err := s.Write(make([]byte, 16))
+	require.NoError(t, err)
+	assert.Equal(t, 16, n)
+	assert.Equal(t, []int{8, 2, 16}, sink.writes)
+
+	s.WriteString("hi", Delim)
+	require.NoError(t, s.Flush())
+	assert.Equal(t, int64(29), s.Flushed())
+	assert.Equal(t, 29, sink.Len())
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, sink.Bytes()[:10])
+	assert.Equal(t, []byte{'h', 'i', Delim}, sink.Bytes()[26:])
+}
+
+func TestStreamWriter_Length(t *testing.T) {
+	sink := &chunkSink{}
+	s := NewStreamWriterWithOrder(sink, 4, binary.BigEndian)
+	s.WriteUInt8(0xFF)
+	mark := s.ReserveLength(2)
+	s.WriteUInt32(1)
+	s.WriteUInt32(2)
+	assert.Empty(t, sink.writes)
+	assert.Equal(t, ErrLengthPending, s.Flush())
+	s.FinishLength(mark)
+	s.WriteUInt8(0xEE)
+	require.NoError(t, s.Flush())
+	require.NoError(t, s.Err())
+	assert.Equal(t, []byte{0xFF, 0, 8, 0, 0, 0, 1, 0, 0, 0, 2, 0xEE}, sink.Bytes())
+}
+
+func TestStreamWriter_LengthAtThreshold(t *testing.T) {
+	// The placeholder reaching the threshold must not flush the bytes before it.
+	sink := &chunkSink{}
+	s := NewStreamWriterWithOrder(sink, 4, binary.BigEndian)
+	s.WriteUInt8(0xFF)
+	s.WriteUInt16(0xEEEE)
+	mark := s.ReserveLength(2)
+	s.WriteUInt8(1)
+	s.FinishLength(mark)
+	require.NoError(t, s.Flush())
+	require.NoError(t, s.Err())
+	assert.Equal(t, []byte{0xFF, 0xEE, 0xEE, 0, 1, 1}, sink.Bytes())
+}
+
+func TestStreamWriter_SinkError(t *testing.T) {
+	broken := errors.New("broken pipe")
+	s := NewStreamWriterWithOrder(&failingWriter{err: broken}, 2, binary.BigEndian)
+	assert.NoError(t, s.WriteByte(1))
+	assert.NoError(t, s.WriteByte(2))
+	assert.Equal(t, broken, s.WriteByte(3))
+	assert.Equal(t, broken, s.Err())
+
+	// Writes after the error only keep the latest value around.
+	s.WriteUInt64(0)
+	s.WriteUInt64(0)
+	assert.Equal(t, 8, s.Size())
+	_, err := s.Write([]byte{1})
+	assert.Equal(t, broken, err)
+	assert.Equal(t, broken, s.Flush())
+}
+
+func TestStreamWriter_RoundTrip(t *testing.T) {
+	var sink bytes.Buffer
+	s := NewStreamWriterWithOrder(&sink, 3, binary.LittleEndian)
+	for i := 0; i < 100; i++ {
+		s.WriteUInt16(uint16(i))
+		s.WriteBigSmart(uint32(i * 1000))
+		s.WriteUVarint(uint64(i * i * i))
+	}
+	require.NoError(t, s.Flush())
+
+	r := NewStreamReaderWithOrder(&sink, 3, binary.LittleEndian)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, uint16(i), r.ReadUInt16())
+		assert.Equal(t, uint32(i*1000), r.ReadBigSmartValue())
+		assert.Equal(t, uint64(i*i*i), r.ReadUVarint())
+	}
+	require.NoError(t, r.Err())
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	return 0, f.err
+}
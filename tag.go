@@ -0,0 +1,163 @@
+package bytepal
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrTag is wrapped by errors for malformed or unsupported bytepal struct tags.
+var ErrTag = errors.New("bytepal: invalid tag")
+
+// Tag is a parsed `bytepal:"..."` struct tag. It is shared by Marshal, Unmarshal and the bytepalgen tool.
+//	The tag is a comma separated list of an encoding followed by options, e.g. "u16,le", "smart",
+//	"string,delim=10", "bits=5", "u8,t=a" or "u16,count=smart" for a count prefixed slice of shorts.
+//	An empty encoding is inferred from the Go type of the field, "-" skips the field.
+type Tag struct {
+	// Type is the encoding of the field or of the elements of an array or slice.
+	Type string
+	// Order is "", "le", "me" or "ime", an empty order follows the byte order of the Reader or Writer.
+	Order string
+	// Transform is applied to the low byte, set with "t=a", "t=c" or "t=s".
+	Transform Transform
+	// Bits is the width of a "bits=N" field.
+	Bits uint
+	// Delim terminates a "string" field, it defaults to 0.
+	Delim byte
+	// Count is the unsigned encoding of the length prefix of a slice.
+	Count string
+	// Skip is set by "-".
+	Skip bool
+}
+
+// Encodings that may be used as the Type of a Tag, with the orders each of them supports.
+var tagOrders = map[string][]string{
+	"u8":        nil,
+	"i8":        nil,
+	"u16":       {"le"},
+	"i16":       {"le"},
+	"u24":       {"le"},
+	"i24":       {"le"},
+	"u32":       {"le", "me", "ime"},
+	"i32":       {"le", "me", "ime"},
+	"u64":       {"le"},
+	"i64":       {"le"},
+	"f32":       {"le"},
+	"f64":       {"le"},
+	"smart":     nil,
+	"ssmart":    nil,
+	"bigsmart":  nil,
+	"nbigsmart": nil,
+	"incrsmart": nil,
+	"uvarint":   nil,
+	"varint":    nil,
+	"quic":      nil,
+	"sqlite":    nil,
+	"compact":   nil,
+	"string":    nil,
+	"bits":      nil,
+}
+
+// Encodings that may carry a transform, by Type and Order.
+var tagTransforms = map[string]bool{
+	"u8": true, "i8": true,
+	"u16": true, "i16": true, "u16,le": true, "i16,le": true,
+	"u32": true, "i32": true, "u32,le": true, "i32,le": true,
+}
+
+// Encodings that may be used as the Count of a Tag.
+var tagCounts = map[string]bool{
+	"u8": true, "u16": true, "u24": true, "u32": true,
+	"smart": true, "bigsmart": true, "incrsmart": true,
+	"uvarint": true, "quic": true, "sqlite": true, "compact": true,
+}
+
+// ParseTag parses the value of a bytepal struct tag.
+func ParseTag(tag string) (Tag, error) {
+	var t Tag
+	if tag == "-" {
+		t.Skip = true
+		return t, nil
+	}
+	if tag == "" {
+		return t, nil
+	}
+	for i, item := range strings.Split(tag, ",") {
+		key, value := item, ""
+		if eq := strings.IndexByte(item, '='); eq >= 0 {
+			key, value = item[:eq], item[eq+1:]
+		}
+		switch {
+		case i == 0 && value == "" && key != "le" && key != "me" && key != "ime":
+			if _, ok := tagOrders[key]; !ok || key == "bits" {
+				return t, fmt.Errorf("%w: unknown encoding %q", ErrTag, key)
+			}
+			t.Type = key
+		case key == "le" || key == "me" || key == "ime":
+			if t.Order != "" || value != "" {
+				return t, fmt.Errorf("%w: unexpected %q", ErrTag, item)
+			}
+			t.Order = key
+		case key == "t":
+			switch value {
+			case "a":
+				t.Transform = TransformA
+			case "c":
+				t.Transform = TransformC
+			case "s":
+				t.Transform = TransformS
+			default:
+				return t, fmt.Errorf("%w: unknown transform %q", ErrTag, value)
+			}
+		case key == "bits":
+			bits, err := strconv.ParseUint(value, 10, 8)
+			if err != nil || bits == 0 || bits > 32 || t.Type != "" {
+				return t, fmt.Errorf("%w: bits must be between 1 and 32, got %q", ErrTag, value)
+			}
+			t.Type, t.Bits = "bits", uint(bits)
+		case key == "delim":
+			delim, err := strconv.ParseUint(value, 0, 8)
+			if err != nil {
+				return t, fmt.Errorf("%w: delimiter %q is not a byte", ErrTag, value)
+			}
+			t.Delim = byte(delim)
+		case key == "count":
+			if !tagCounts[value] {
+				return t, fmt.Errorf("%w: %q can not be used as a count", ErrTag, value)
+			}
+			t.Count = value
+		default:
+			return t, fmt.Errorf("%w: unknown option %q", ErrTag, item)
+		}
+	}
+	return t, t.validate()
+}
+
+// validate checks the options of t are supported by its encoding.
+func (t Tag) validate() error {
+	if t.Order != "" {
+		supported := false
+		for _, order := range tagOrders[t.Type] {
+			supported = supported || order == t.Order
+		}
+		if !supported {
+			return fmt.Errorf("%w: %q does not support order %q", ErrTag, t.Type, t.Order)
+		}
+	}
+	if t.Transform != TransformNone && !tagTransforms[t.Key()] {
+		return fmt.Errorf("%w: %q does not support transforms", ErrTag, t.Key())
+	}
+	if t.Delim != 0 && t.Type != "string" {
+		return fmt.Errorf("%w: delim is only supported by string", ErrTag)
+	}
+	return nil
+}
+
+// Key returns the encoding of t with its order, such as "u16" or "u32,le".
+func (t Tag) Key() string {
+	if t.Order == "" {
+		return t.Type
+	}
+	return t.Type + "," + t.Order
+}
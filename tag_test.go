@@ -0,0 +1,40 @@
+package bytepal
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := map[string]Tag{
+		"":                  {},
+		"-":                 {Skip: true},
+		"u16,le":            {Type: "u16", Order: "le"},
+		"smart":             {Type: "smart"},
+		"string,delim=10":   {Type: "string", Delim: 10},
+		"string,delim=0x0A": {Type: "string", Delim: 10},
+		"bits=5":            {Type: "bits", Bits: 5},
+		"u8,t=a":            {Type: "u8", Transform: TransformA},
+		"i32,le,t=s":        {Type: "i32", Order: "le", Transform: TransformS},
+		"u16,count=smart":   {Type: "u16", Count: "smart"},
+		"count=u8":          {Count: "u8"},
+		"u32,ime":           {Type: "u32", Order: "ime"},
+	}
+	for value, expected := range tests {
+		tag, err := ParseTag(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, tag, value)
+	}
+}
+
+func TestParseTag_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"u12", "bits", "bits=0", "bits=33", "u16,bits=4", "u8,le", "u64,me", "u64,t=a", "u24,t=c",
+		"u8,t=x", "string,delim=256", "u8,delim=1", "u8,count=i8", "u8,count=string", "u16,le,le", "u8,foo",
+	} {
+		_, err := ParseTag(value)
+		assert.True(t, errors.Is(err, ErrTag), value)
+	}
+}
@@ -0,0 +1,870 @@
+package bytepal
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Pwalne/bytepal/hexdump"
+)
+
+// Trace records the reads of a TracedReader or the writes of a TracedWriter. A call made by another
+//	traced call, such as the ReadUInt8 of ReadSmart, is part of the entry of the outer call.
+//	A Trace must not be shared by several readers or writers.
+type Trace struct {
+	Entries []TraceEntry
+}
+
+// TraceEntry is a single traced call.
+type TraceEntry struct {
+	// Method is the name of the call, bit fields are recorded as ReadBits and WriteBits.
+	Method string
+	// Offset and Size are the bytes passed over. Offsets of a StreamWriter include the flushed bytes.
+	Offset int
+	Size   int
+	// Bit and Bits locate a bit field within its bytes, Bits is 0 for byte operations.
+	Bit  int
+	Bits int
+	// Value is the value read or written.
+	Value interface{}
+	// Failed is set if the call failed or followed an earlier failure.
+	Failed bool
+}
+
+// add records entry.
+func (t *Trace) add(entry TraceEntry) {
+	// Callers reuse their buffers, the recorded bytes must not change with them.
+	if v, ok := entry.Value.([]byte); ok {
+		entry.Value = append([]byte(nil), v...)
+	}
+	t.Entries = append(t.Entries, entry)
+}
+
+// TracedReader reads like its Reader and records every read into a Trace. The reads of the embedded
+//	Reader, and of Readers returned by Limit and ReadRSABlock, are not traced, so a Reader that is
+//	not wrapped pays nothing for tracing.
+type TracedReader struct {
+	*Reader
+	trace *Trace
+}
+
+// NewTracedReader traces the reads of r into t.
+func NewTracedReader(r *Reader, t *Trace) *TracedReader {
+	return &TracedReader{Reader: r, trace: t}
+}
+
+// record ends the read started at start, v points at the value read. A read that panicked is
+//	recorded as failed before the panic continues, so record must be deferred by the read itself.
+func (r *TracedReader) record(method string, start int, v interface{}) {
+	rec := recover()
+	r.trace.add(TraceEntry{
+		Method: method,
+		Offset: start,
+		Size:   r.currentIndex - start,
+		Value:  reflect.ValueOf(v).Elem().Interface(),
+		Failed: rec != nil || r.err != nil,
+	})
+	if rec != nil {
+		panic(rec)
+	}
+}
+
+// Read traces Reader.Read.
+func (r *TracedReader) Read(p []byte) (n int, err error) {
+	defer r.record("Read", r.currentIndex, &p)
+	n, err = r.Reader.Read(p)
+	p = p[:n]
+	return n, err
+}
+
+// ReadByte traces Reader.ReadByte.
+func (r *TracedReader) ReadByte() (c byte, err error) {
+	defer r.record("ReadByte", r.currentIndex, &c)
+	return r.Reader.ReadByte()
+}
+
+// ReadBytes traces Reader.ReadBytes.
+func (r *TracedReader) ReadBytes(payload []byte) {
+	defer r.record("ReadBytes", r.currentIndex, &payload)
+	r.Reader.ReadBytes(payload)
+}
+
+// Inc traces Reader.Inc.
+func (r *TracedReader) Inc(amt int) {
+	defer r.record("Inc", r.currentIndex, &amt)
+	r.Reader.Inc(amt)
+}
+
+// ReadBits traces the bit reads of Reader.ReadBits.
+func (r *TracedReader) ReadBits() func(uint) uint {
+	read := r.Reader.ReadBits()
+	bitPosition := r.currentIndex * 8
+	return func(numBits uint) (v uint) {
+		defer r.recordBits(bitPosition, numBits, &v)
+		bitPosition += int(numBits)
+		return read(numBits)
+	}
+}
+
+// recordBits ends the read of numBits at bit position start.
+func (r *TracedReader) recordBits(start int, numBits uint, v *uint) {
+	rec := recover()
+	r.trace.add(bitsEntry("ReadBits", start, numBits, *v, rec != nil || r.err != nil))
+	if rec != nil {
+		panic(rec)
+	}
+}
+
+// ReadOpcode traces Reader.ReadOpcode.
+func (r *TracedReader) ReadOpcode(cipher Cipher) (v uint8) {
+	defer r.record("ReadOpcode", r.currentIndex, &v)
+	return r.Reader.ReadOpcode(cipher)
+}
+
+// ReadUInt8 traces Reader.ReadUInt8.
+func (r *TracedReader) ReadUInt8() (v uint8) {
+	defer r.record("ReadUInt8", r.currentIndex, &v)
+	return r.Reader.ReadUInt8()
+}
+
+// ReadInt8 traces Reader.ReadInt8.
+func (r *TracedReader) ReadInt8() (v int8) {
+	defer r.record("ReadInt8", r.currentIndex, &v)
+	return r.Reader.ReadInt8()
+}
+
+// ReadSlice traces Reader.ReadSlice.
+func (r *TracedReader) ReadSlice(size int) (v []byte) {
+	defer r.record("ReadSlice", r.currentIndex, &v)
+	return r.Reader.ReadSlice(size)
+}
+
+// ReadLEUInt16 traces Reader.ReadLEUInt16.
+func (r *TracedReader) ReadLEUInt16() (v uint16) {
+	defer r.record("ReadLEUInt16", r.currentIndex, &v)
+	return r.Reader.ReadLEUInt16()
+}
+
+// ReadLEInt16 traces Reader.ReadLEInt16.
+func (r *TracedReader) ReadLEInt16() (v int16) {
+	defer r.record("ReadLEInt16", r.currentIndex, &v)
+	return r.Reader.ReadLEInt16()
+}
+
+// ReadUInt16 traces Reader.ReadUInt16.
+func (r *TracedReader) ReadUInt16() (v uint16) {
+	defer r.record("ReadUInt16", r.currentIndex, &v)
+	return r.Reader.ReadUInt16()
+}
+
+// ReadInt16 traces Reader.ReadInt16.
+func (r *TracedReader) ReadInt16() (v int16) {
+	defer r.record("ReadInt16", r.currentIndex, &v)
+	return r.Reader.ReadInt16()
+}
+
+// ReadUMedium traces Reader.ReadUMedium.
+func (r *TracedReader) ReadUMedium() (v uint32) {
+	defer r.record("ReadUMedium", r.currentIndex, &v)
+	return r.Reader.ReadUMedium()
+}
+
+// ReadMedium traces Reader.ReadMedium.
+func (r *TracedReader) ReadMedium() (v int32) {
+	defer r.record("ReadMedium", r.currentIndex, &v)
+	return r.Reader.ReadMedium()
+}
+
+// ReadLEUMedium traces Reader.ReadLEUMedium.
+func (r *TracedReader) ReadLEUMedium() (v uint32) {
+	defer r.record("ReadLEUMedium", r.currentIndex, &v)
+	return r.Reader.ReadLEUMedium()
+}
+
+// ReadLEMedium traces Reader.ReadLEMedium.
+func (r *TracedReader) ReadLEMedium() (v int32) {
+	defer r.record("ReadLEMedium", r.currentIndex, &v)
+	return r.Reader.ReadLEMedium()
+}
+
+// ReadBigSmart traces Reader.ReadBigSmart.
+func (r *TracedReader) ReadBigSmart() (v uint32) {
+	defer r.record("ReadBigSmart", r.currentIndex, &v)
+	return r.Reader.ReadBigSmart()
+}
+
+// ReadBigSmartValue traces Reader.ReadBigSmartValue.
+func (r *TracedReader) ReadBigSmartValue() (v uint32) {
+	defer r.record("ReadBigSmartValue", r.currentIndex, &v)
+	return r.Reader.ReadBigSmartValue()
+}
+
+// ReadLEUInt32 traces Reader.ReadLEUInt32.
+func (r *TracedReader) ReadLEUInt32() (v uint32) {
+	defer r.record("ReadLEUInt32", r.currentIndex, &v)
+	return r.Reader.ReadLEUInt32()
+}
+
+// ReadLEInt32 traces Reader.ReadLEInt32.
+func (r *TracedReader) ReadLEInt32() (v int32) {
+	defer r.record("ReadLEInt32", r.currentIndex, &v)
+	return r.Reader.ReadLEInt32()
+}
+
+// ReadUInt32 traces Reader.ReadUInt32.
+func (r *TracedReader) ReadUInt32() (v uint32) {
+	defer r.record("ReadUInt32", r.currentIndex, &v)
+	return r.Reader.ReadUInt32()
+}
+
+// ReadInt32 traces Reader.ReadInt32.
+func (r *TracedReader) ReadInt32() (v int32) {
+	defer r.record("ReadInt32", r.currentIndex, &v)
+	return r.Reader.ReadInt32()
+}
+
+// ReadMEUInt32 traces Reader.ReadMEUInt32.
+func (r *TracedReader) ReadMEUInt32() (v uint32) {
+	defer r.record("ReadMEUInt32", r.currentIndex, &v)
+	return r.Reader.ReadMEUInt32()
+}
+
+// ReadMEInt32 traces Reader.ReadMEInt32.
+func (r *TracedReader) ReadMEInt32() (v int32) {
+	defer r.record("ReadMEInt32", r.currentIndex, &v)
+	return r.Reader.ReadMEInt32()
+}
+
+// ReadIMEUInt32 traces Reader.ReadIMEUInt32.
+func (r *TracedReader) ReadIMEUInt32() (v uint32) {
+	defer r.record("ReadIMEUInt32", r.currentIndex, &v)
+	return r.Reader.ReadIMEUInt32()
+}
+
+// ReadIMEInt32 traces Reader.ReadIMEInt32.
+func (r *TracedReader) ReadIMEInt32() (v int32) {
+	defer r.record("ReadIMEInt32", r.currentIndex, &v)
+	return r.Reader.ReadIMEInt32()
+}
+
+// ReadLEUInt64 traces Reader.ReadLEUInt64.
+func (r *TracedReader) ReadLEUInt64() (v uint64) {
+	defer r.record("ReadLEUInt64", r.currentIndex, &v)
+	return r.Reader.ReadLEUInt64()
+}
+
+// ReadLEInt64 traces Reader.ReadLEInt64.
+func (r *TracedReader) ReadLEInt64() (v int64) {
+	defer r.record("ReadLEInt64", r.currentIndex, &v)
+	return r.Reader.ReadLEInt64()
+}
+
+// ReadUInt64 traces Reader.ReadUInt64.
+func (r *TracedReader) ReadUInt64() (v uint64) {
+	defer r.record("ReadUInt64", r.currentIndex, &v)
+	return r.Reader.ReadUInt64()
+}
+
+// ReadInt64 traces Reader.ReadInt64.
+func (r *TracedReader) ReadInt64() (v int64) {
+	defer r.record("ReadInt64", r.currentIndex, &v)
+	return r.Reader.ReadInt64()
+}
+
+// ReadFloat32 traces Reader.ReadFloat32.
+func (r *TracedReader) ReadFloat32() (v float32) {
+	defer r.record("ReadFloat32", r.currentIndex, &v)
+	return r.Reader.ReadFloat32()
+}
+
+// ReadLEFloat32 traces Reader.ReadLEFloat32.
+func (r *TracedReader) ReadLEFloat32() (v float32) {
+	defer r.record("ReadLEFloat32", r.currentIndex, &v)
+	return r.Reader.ReadLEFloat32()
+}
+
+// ReadFloat64 traces Reader.ReadFloat64.
+func (r *TracedReader) ReadFloat64() (v float64) {
+	defer r.record("ReadFloat64", r.currentIndex, &v)
+	return r.Reader.ReadFloat64()
+}
+
+// ReadLEFloat64 traces Reader.ReadLEFloat64.
+func (r *TracedReader) ReadLEFloat64() (v float64) {
+	defer r.record("ReadLEFloat64", r.currentIndex, &v)
+	return r.Reader.ReadLEFloat64()
+}
+
+// ReadString traces Reader.ReadString.
+func (r *TracedReader) ReadString(delim byte) (v string) {
+	defer r.record("ReadString", r.currentIndex, &v)
+	return r.Reader.ReadString(delim)
+}
+
+// ReadSmart traces Reader.ReadSmart.
+func (r *TracedReader) ReadSmart() (v uint16) {
+	defer r.record("ReadSmart", r.currentIndex, &v)
+	return r.Reader.ReadSmart()
+}
+
+// ReadSignedSmart traces Reader.ReadSignedSmart.
+func (r *TracedReader) ReadSignedSmart() (v int16) {
+	defer r.record("ReadSignedSmart", r.currentIndex, &v)
+	return r.Reader.ReadSignedSmart()
+}
+
+// ReadNullableBigSmart traces Reader.ReadNullableBigSmart.
+func (r *TracedReader) ReadNullableBigSmart() (v int32) {
+	defer r.record("ReadNullableBigSmart", r.currentIndex, &v)
+	return r.Reader.ReadNullableBigSmart()
+}
+
+// ReadIncrSmart traces Reader.ReadIncrSmart.
+func (r *TracedReader) ReadIncrSmart() (v int) {
+	defer r.record("ReadIncrSmart", r.currentIndex, &v)
+	return r.Reader.ReadIncrSmart()
+}
+
+// ReadUInt8T traces Reader.ReadUInt8T.
+func (r *TracedReader) ReadUInt8T(t Transform) (v uint8) {
+	defer r.record("ReadUInt8T", r.currentIndex, &v)
+	return r.Reader.ReadUInt8T(t)
+}
+
+// ReadInt8T traces Reader.ReadInt8T.
+func (r *TracedReader) ReadInt8T(t Transform) (v int8) {
+	defer r.record("ReadInt8T", r.currentIndex, &v)
+	return r.Reader.ReadInt8T(t)
+}
+
+// ReadUInt16T traces Reader.ReadUInt16T.
+func (r *TracedReader) ReadUInt16T(t Transform) (v uint16) {
+	defer r.record("ReadUInt16T", r.currentIndex, &v)
+	return r.Reader.ReadUInt16T(t)
+}
+
+// ReadInt16T traces Reader.ReadInt16T.
+func (r *TracedReader) ReadInt16T(t Transform) (v int16) {
+	defer r.record("ReadInt16T", r.currentIndex, &v)
+	return r.Reader.ReadInt16T(t)
+}
+
+// ReadLEUInt16T traces Reader.ReadLEUInt16T.
+func (r *TracedReader) ReadLEUInt16T(t Transform) (v uint16) {
+	defer r.record("ReadLEUInt16T", r.currentIndex, &v)
+	return r.Reader.ReadLEUInt16T(t)
+}
+
+// ReadLEInt16T traces Reader.ReadLEInt16T.
+func (r *TracedReader) ReadLEInt16T(t Transform) (v int16) {
+	defer r.record("ReadLEInt16T", r.currentIndex, &v)
+	return r.Reader.ReadLEInt16T(t)
+}
+
+// ReadUInt32T traces Reader.ReadUInt32T.
+func (r *TracedReader) ReadUInt32T(t Transform) (v uint32) {
+	defer r.record("ReadUInt32T", r.currentIndex, &v)
+	return r.Reader.ReadUInt32T(t)
+}
+
+// ReadInt32T traces Reader.ReadInt32T.
+func (r *TracedReader) ReadInt32T(t Transform) (v int32) {
+	defer r.record("ReadInt32T", r.currentIndex, &v)
+	return r.Reader.ReadInt32T(t)
+}
+
+// ReadLEUInt32T traces Reader.ReadLEUInt32T.
+func (r *TracedReader) ReadLEUInt32T(t Transform) (v uint32) {
+	defer r.record("ReadLEUInt32T", r.currentIndex, &v)
+	return r.Reader.ReadLEUInt32T(t)
+}
+
+// ReadLEInt32T traces Reader.ReadLEInt32T.
+func (r *TracedReader) ReadLEInt32T(t Transform) (v int32) {
+	defer r.record("ReadLEInt32T", r.currentIndex, &v)
+	return r.Reader.ReadLEInt32T(t)
+}
+
+// ReadUVarint traces Reader.ReadUVarint.
+func (r *TracedReader) ReadUVarint() (v uint64) {
+	defer r.record("ReadUVarint", r.currentIndex, &v)
+	return r.Reader.ReadUVarint()
+}
+
+// ReadUVarint32 traces Reader.ReadUVarint32.
+func (r *TracedReader) ReadUVarint32() (v uint32) {
+	defer r.record("ReadUVarint32", r.currentIndex, &v)
+	return r.Reader.ReadUVarint32()
+}
+
+// ReadVarint traces Reader.ReadVarint.
+func (r *TracedReader) ReadVarint() (v int64) {
+	defer r.record("ReadVarint", r.currentIndex, &v)
+	return r.Reader.ReadVarint()
+}
+
+// ReadVarint32 traces Reader.ReadVarint32.
+func (r *TracedReader) ReadVarint32() (v int32) {
+	defer r.record("ReadVarint32", r.currentIndex, &v)
+	return r.Reader.ReadVarint32()
+}
+
+// ReadQUICVarint traces Reader.ReadQUICVarint.
+func (r *TracedReader) ReadQUICVarint() (v uint64) {
+	defer r.record("ReadQUICVarint", r.currentIndex, &v)
+	return r.Reader.ReadQUICVarint()
+}
+
+// ReadSQLiteVarint traces Reader.ReadSQLiteVarint.
+func (r *TracedReader) ReadSQLiteVarint() (v uint64) {
+	defer r.record("ReadSQLiteVarint", r.currentIndex, &v)
+	return r.Reader.ReadSQLiteVarint()
+}
+
+// ReadCompactSize traces Reader.ReadCompactSize.
+func (r *TracedReader) ReadCompactSize() (v uint64) {
+	defer r.record("ReadCompactSize", r.currentIndex, &v)
+	return r.Reader.ReadCompactSize()
+}
+
+// TracedWriter writes like its Writer and records every write into a Trace. Offsets of a StreamWriter
+//	include the flushed bytes.
+type TracedWriter struct {
+	Writer
+	trace *Trace
+}
+
+// NewTracedWriter traces the writes of w into t.
+func NewTracedWriter(w Writer, t *Trace) *TracedWriter {
+	return &TracedWriter{Writer: w, trace: t}
+}
+
+// tracedWriter is implemented by the writers of this package.
+type tracedWriter interface {
+	// position is the offset of the next byte written.
+	position() int
+	// written is the number of bytes after the placeholder of mark.
+	written(mark LengthMark) int
+}
+
+// position is the offset of the next byte written, or Size for writers of other packages.
+func (w *TracedWriter) position() int {
+	if t, ok := w.Writer.(tracedWriter); ok {
+		return t.position()
+	}
+	return w.Size()
+}
+
+// record ends the write of v started at start. A panicking write is recorded as failed before the
+//	panic continues.
+func (w *TracedWriter) record(method string, start int, v interface{}) {
+	rec := recover()
+	w.trace.add(TraceEntry{
+		Method: method,
+		Offset: start,
+		Size:   w.position() - start,
+		Value:  v,
+		Failed: rec != nil || w.Err() != nil,
+	})
+	if rec != nil {
+		panic(rec)
+	}
+}
+
+// BitAccess traces the bit writes of Writer.BitAccess.
+func (w *TracedWriter) BitAccess() func(uint, uint) {
+	write := w.Writer.BitAccess()
+	bitPosition := w.position() * 8
+	return func(numBits, value uint) {
+		defer w.recordBits(bitPosition, numBits, value)
+		bitPosition += int(numBits)
+		write(numBits, value)
+	}
+}
+
+// recordBits ends the write of value in numBits at bit position start.
+func (w *TracedWriter) recordBits(start int, numBits, value uint) {
+	rec := recover()
+	w.trace.add(bitsEntry("WriteBits", start, numBits, value, rec != nil || w.Err() != nil))
+	if rec != nil {
+		panic(rec)
+	}
+}
+
+// Write traces Writer.Write.
+func (w *TracedWriter) Write(v []byte) (int, error) {
+	defer w.record("Write", w.position(), v)
+	return w.Writer.Write(v)
+}
+
+// WriteByte traces Writer.WriteByte.
+func (w *TracedWriter) WriteByte(c byte) error {
+	defer w.record("WriteByte", w.position(), c)
+	return w.Writer.WriteByte(c)
+}
+
+// WriteString traces Writer.WriteString.
+func (w *TracedWriter) WriteString(s string, delim byte) {
+	defer w.record("WriteString", w.position(), s)
+	w.Writer.WriteString(s, delim)
+}
+
+// WriteOpcode traces Writer.WriteOpcode.
+func (w *TracedWriter) WriteOpcode(cipher Cipher, op uint8) {
+	defer w.record("WriteOpcode", w.position(), op)
+	w.Writer.WriteOpcode(cipher, op)
+}
+
+// ReserveLength traces Writer.ReserveLength.
+func (w *TracedWriter) ReserveLength(size int) LengthMark {
+	defer w.record("ReserveLength", w.position(), size)
+	return w.Writer.ReserveLength(size)
+}
+
+// FinishLength traces Writer.FinishLength, the value is the length patched in.
+func (w *TracedWriter) FinishLength(mark LengthMark) {
+	length := 0
+	if t, ok := w.Writer.(tracedWriter); ok {
+		length = t.written(mark)
+	}
+	defer w.record("FinishLength", w.position(), length)
+	w.Writer.FinishLength(mark)
+}
+
+// WriteUInt8 traces Writer.WriteUInt8.
+func (w *TracedWriter) WriteUInt8(v uint8) {
+	defer w.record("WriteUInt8", w.position(), v)
+	w.Writer.WriteUInt8(v)
+}
+
+// WriteInt8 traces Writer.WriteInt8.
+func (w *TracedWriter) WriteInt8(v int8) {
+	defer w.record("WriteInt8", w.position(), v)
+	w.Writer.WriteInt8(v)
+}
+
+// WriteInt16 traces Writer.WriteInt16.
+func (w *TracedWriter) WriteInt16(v int16) {
+	defer w.record("WriteInt16", w.position(), v)
+	w.Writer.WriteInt16(v)
+}
+
+// WriteUInt16 traces Writer.WriteUInt16.
+func (w *TracedWriter) WriteUInt16(v uint16) {
+	defer w.record("WriteUInt16", w.position(), v)
+	w.Writer.WriteUInt16(v)
+}
+
+// WriteLEInt16 traces Writer.WriteLEInt16.
+func (w *TracedWriter) WriteLEInt16(v int16) {
+	defer w.record("WriteLEInt16", w.position(), v)
+	w.Writer.WriteLEInt16(v)
+}
+
+// WriteLEUInt16 traces Writer.WriteLEUInt16.
+func (w *TracedWriter) WriteLEUInt16(v uint16) {
+	defer w.record("WriteLEUInt16", w.position(), v)
+	w.Writer.WriteLEUInt16(v)
+}
+
+// WriteMedium traces Writer.WriteMedium.
+func (w *TracedWriter) WriteMedium(v int32) {
+	defer w.record("WriteMedium", w.position(), v)
+	w.Writer.WriteMedium(v)
+}
+
+// WriteLEMedium traces Writer.WriteLEMedium.
+func (w *TracedWriter) WriteLEMedium(v int32) {
+	defer w.record("WriteLEMedium", w.position(), v)
+	w.Writer.WriteLEMedium(v)
+}
+
+// WriteInt32 traces Writer.WriteInt32.
+func (w *TracedWriter) WriteInt32(v int32) {
+	defer w.record("WriteInt32", w.position(), v)
+	w.Writer.WriteInt32(v)
+}
+
+// WriteUInt32 traces Writer.WriteUInt32.
+func (w *TracedWriter) WriteUInt32(v uint32) {
+	defer w.record("WriteUInt32", w.position(), v)
+	w.Writer.WriteUInt32(v)
+}
+
+// WriteLEInt32 traces Writer.WriteLEInt32.
+func (w *TracedWriter) WriteLEInt32(v int32) {
+	defer w.record("WriteLEInt32", w.position(), v)
+	w.Writer.WriteLEInt32(v)
+}
+
+// WriteLEUInt32 traces Writer.WriteLEUInt32.
+func (w *TracedWriter) WriteLEUInt32(v uint32) {
+	defer w.record("WriteLEUInt32", w.position(), v)
+	w.Writer.WriteLEUInt32(v)
+}
+
+// WriteMEInt32 traces Writer.WriteMEInt32.
+func (w *TracedWriter) WriteMEInt32(v int32) {
+	defer w.record("WriteMEInt32", w.position(), v)
+	w.Writer.WriteMEInt32(v)
+}
+
+// WriteMEUInt32 traces Writer.WriteMEUInt32.
+func (w *TracedWriter) WriteMEUInt32(v uint32) {
+	defer w.record("WriteMEUInt32", w.position(), v)
+	w.Writer.WriteMEUInt32(v)
+}
+
+// WriteIMEInt32 traces Writer.WriteIMEInt32.
+func (w *TracedWriter) WriteIMEInt32(v int32) {
+	defer w.record("WriteIMEInt32", w.position(), v)
+	w.Writer.WriteIMEInt32(v)
+}
+
+// WriteIMEUInt32 traces Writer.WriteIMEUInt32.
+func (w *TracedWriter) WriteIMEUInt32(v uint32) {
+	defer w.record("WriteIMEUInt32", w.position(), v)
+	w.Writer.WriteIMEUInt32(v)
+}
+
+// WriteInt64 traces Writer.WriteInt64.
+func (w *TracedWriter) WriteInt64(v int64) {
+	defer w.record("WriteInt64", w.position(), v)
+	w.Writer.WriteInt64(v)
+}
+
+// WriteUInt64 traces Writer.WriteUInt64.
+func (w *TracedWriter) WriteUInt64(v uint64) {
+	defer w.record("WriteUInt64", w.position(), v)
+	w.Writer.WriteUInt64(v)
+}
+
+// WriteLEInt64 traces Writer.WriteLEInt64.
+func (w *TracedWriter) WriteLEInt64(v int64) {
+	defer w.record("WriteLEInt64", w.position(), v)
+	w.Writer.WriteLEInt64(v)
+}
+
+// WriteLEUInt64 traces Writer.WriteLEUInt64.
+func (w *TracedWriter) WriteLEUInt64(v uint64) {
+	defer w.record("WriteLEUInt64", w.position(), v)
+	w.Writer.WriteLEUInt64(v)
+}
+
+// WriteFloat32 traces Writer.WriteFloat32.
+func (w *TracedWriter) WriteFloat32(v float32) {
+	defer w.record("WriteFloat32", w.position(), v)
+	w.Writer.WriteFloat32(v)
+}
+
+// WriteLEFloat32 traces Writer.WriteLEFloat32.
+func (w *TracedWriter) WriteLEFloat32(v float32) {
+	defer w.record("WriteLEFloat32", w.position(), v)
+	w.Writer.WriteLEFloat32(v)
+}
+
+// WriteFloat64 traces Writer.WriteFloat64.
+func (w *TracedWriter) WriteFloat64(v float64) {
+	defer w.record("WriteFloat64", w.position(), v)
+	w.Writer.WriteFloat64(v)
+}
+
+// WriteLEFloat64 traces Writer.WriteLEFloat64.
+func (w *TracedWriter) WriteLEFloat64(v float64) {
+	defer w.record("WriteLEFloat64", w.position(), v)
+	w.Writer.WriteLEFloat64(v)
+}
+
+// WriteSmart traces Writer.WriteSmart.
+func (w *TracedWriter) WriteSmart(v uint16) {
+	defer w.record("WriteSmart", w.position(), v)
+	w.Writer.WriteSmart(v)
+}
+
+// WriteSignedSmart traces Writer.WriteSignedSmart.
+func (w *TracedWriter) WriteSignedSmart(v int16) {
+	defer w.record("WriteSignedSmart", w.position(), v)
+	w.Writer.WriteSignedSmart(v)
+}
+
+// WriteBigSmart traces Writer.WriteBigSmart.
+func (w *TracedWriter) WriteBigSmart(v uint32) {
+	defer w.record("WriteBigSmart", w.position(), v)
+	w.Writer.WriteBigSmart(v)
+}
+
+// WriteNullableBigSmart traces Writer.WriteNullableBigSmart.
+func (w *TracedWriter) WriteNullableBigSmart(v int32) {
+	defer w.record("WriteNullableBigSmart", w.position(), v)
+	w.Writer.WriteNullableBigSmart(v)
+}
+
+// WriteIncrSmart traces Writer.WriteIncrSmart.
+func (w *TracedWriter) WriteIncrSmart(v int) {
+	defer w.record("WriteIncrSmart", w.position(), v)
+	w.Writer.WriteIncrSmart(v)
+}
+
+// WriteUVarint traces Writer.WriteUVarint.
+func (w *TracedWriter) WriteUVarint(v uint64) {
+	defer w.record("WriteUVarint", w.position(), v)
+	w.Writer.WriteUVarint(v)
+}
+
+// WriteVarint traces Writer.WriteVarint.
+func (w *TracedWriter) WriteVarint(v int64) {
+	defer w.record("WriteVarint", w.position(), v)
+	w.Writer.WriteVarint(v)
+}
+
+// WriteQUICVarint traces Writer.WriteQUICVarint.
+func (w *TracedWriter) WriteQUICVarint(v uint64) {
+	defer w.record("WriteQUICVarint", w.position(), v)
+	w.Writer.WriteQUICVarint(v)
+}
+
+// WriteSQLiteVarint traces Writer.WriteSQLiteVarint.
+func (w *TracedWriter) WriteSQLiteVarint(v uint64) {
+	defer w.record("WriteSQLiteVarint", w.position(), v)
+	w.Writer.WriteSQLiteVarint(v)
+}
+
+// WriteCompactSize traces Writer.WriteCompactSize.
+func (w *TracedWriter) WriteCompactSize(v uint64) {
+	defer w.record("WriteCompactSize", w.position(), v)
+	w.Writer.WriteCompactSize(v)
+}
+
+// WriteUInt8T traces Writer.WriteUInt8T.
+func (w *TracedWriter) WriteUInt8T(v uint8, t Transform) {
+	defer w.record("WriteUInt8T", w.position(), v)
+	w.Writer.WriteUInt8T(v, t)
+}
+
+// WriteInt8T traces Writer.WriteInt8T.
+func (w *TracedWriter) WriteInt8T(v int8, t Transform) {
+	defer w.record("WriteInt8T", w.position(), v)
+	w.Writer.WriteInt8T(v, t)
+}
+
+// WriteInt16T traces Writer.WriteInt16T.
+func (w *TracedWriter) WriteInt16T(v int16, t Transform) {
+	defer w.record("WriteInt16T", w.position(), v)
+	w.Writer.WriteInt16T(v, t)
+}
+
+// WriteLEInt16T traces Writer.WriteLEInt16T.
+func (w *TracedWriter) WriteLEInt16T(v int16, t Transform) {
+	defer w.record("WriteLEInt16T", w.position(), v)
+	w.Writer.WriteLEInt16T(v, t)
+}
+
+// WriteInt32T traces Writer.WriteInt32T.
+func (w *TracedWriter) WriteInt32T(v int32, t Transform) {
+	defer w.record("WriteInt32T", w.position(), v)
+	w.Writer.WriteInt32T(v, t)
+}
+
+// WriteLEInt32T traces Writer.WriteLEInt32T.
+func (w *TracedWriter) WriteLEInt32T(v int32, t Transform) {
+	defer w.record("WriteLEInt32T", w.position(), v)
+	w.Writer.WriteLEInt32T(v, t)
+}
+
+func bitsEntry(method string, start int, bits, v uint, failed bool) TraceEntry {
+	return TraceEntry{
+		Method: method,
+		Offset: start / 8,
+		Size:   (start%8 + int(bits) + 7) / 8,
+		Bit:    start % 8,
+		Bits:   int(bits),
+		Value:  v,
+		Failed: failed,
+	}
+}
+
+// String formats the entry as its offset, length, method and value.
+func (e TraceEntry) String() string {
+	return e.format(0)
+}
+
+// format pads the method to width.
+func (e TraceEntry) format(width int) string {
+	offset := fmt.Sprintf("%08x", e.Offset)
+	length := fmt.Sprintf("%d bytes", e.Size)
+	if e.Bits > 0 {
+		offset += fmt.Sprintf(".%d", e.Bit)
+		length = fmt.Sprintf("%d bits", e.Bits)
+	}
+	line := fmt.Sprintf("%-10s  %-8s  %-*s  %s", offset, length, width, e.Method, hexdump.FormatValue(e.Value))
+	if e.Failed {
+		line += " (failed)"
+	}
+	return strings.TrimRight(line, " ")
+}
+
+// String formats every entry on a line of its own.
+func (t *Trace) String() string {
+	width := 0
+	for _, e := range t.Entries {
+		if len(e.Method) > width {
+			width = len(e.Method)
+		}
+	}
+	var s strings.Builder
+	for _, e := range t.Entries {
+		s.WriteString(e.format(width))
+		s.WriteByte('\n')
+	}
+	return s.String()
+}
+
+// Diff compares the entries of t and other by position and returns the differing entries, those of t
+//	prefixed by "-" and those of other by "+", or "" if they match. Method names are compared without
+//	their Read or Write prefix, so the trace of a Reader can be diffed against the trace of the Writer
+//	that encoded its input.
+func (t *Trace) Diff(other *Trace) string {
+	var s strings.Builder
+	for i := 0; i < len(t.Entries) || i < len(other.Entries); i++ {
+		var a, b *TraceEntry
+		if i < len(t.Entries) {
+			a = &t.Entries[i]
+		}
+		if i < len(other.Entries) {
+			b = &other.Entries[i]
+		}
+		if a != nil && b != nil && a.key() == b.key() {
+			continue
+		}
+		fmt.Fprintf(&s, "entry %d:\n", i)
+		if a != nil {
+			fmt.Fprintf(&s, "- %s\n", a)
+		}
+		if b != nil {
+			fmt.Fprintf(&s, "+ %s\n", b)
+		}
+	}
+	return s.String()
+}
+
+// key is the entry as compared by Diff.
+func (e TraceEntry) key() string {
+	method := strings.TrimPrefix(strings.TrimPrefix(e.Method, "Read"), "Write")
+	return fmt.Sprintf("%d %d %d %d %s %s %t", e.Offset, e.Size, e.Bit, e.Bits, method, hexdump.FormatValue(e.Value), e.Failed)
+}
+
+// Spans converts the entries for hexdump.Annotate, named by method and typed by the Go type of the value.
+//	The spans can be stored with hexdump.WriteSpans for the -trace flag of the bytepal dump command.
+func (t *Trace) Spans() []hexdump.Span {
+	spans := make([]hexdump.Span, len(t.Entries))
+	for i, e := range t.Entries {
+		spans[i] = hexdump.Span{
+			Offset: e.Offset, Size: e.Size, Bit: e.Bit, Bits: e.Bits,
+			Name: e.Method, Type: fmt.Sprintf("%T", e.Value), Value: e.Value,
+		}
+		if e.Failed {
+			spans[i].Name += " (failed)"
+		}
+	}
+	return spans
+}
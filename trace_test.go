@@ -0,0 +1,178 @@
+package bytepal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/Pwalne/bytepal/hexdump"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader_Trace(t *testing.T) {
+	trace := &Trace{}
+	r := NewTracedReader(NewReader([]byte{0x10, 0x37, 0x80, 0x00, 0x00, 0x01, 0xB4, 'h', 'i', 0, 9, 1, 2}), trace)
+	r.ReadUInt16()
+	r.ReadBigSmart()
+	bits := r.ReadBits()
+	bits(3)
+	bits(5)
+	r.ReadString(0)
+	r.Inc(1)
+	payload := make([]byte, 2)
+	r.ReadBytes(payload)
+	// Reads of the embedded Reader are not traced.
+	r.SetCurrentRead(0)
+	r.Reader.ReadUInt8()
+
+	assert.Equal(t, []TraceEntry{
+		{Method: "ReadUInt16", Offset: 0, Size: 2, Value: uint16(4151)},
+		{Method: "ReadBigSmart", Offset: 2, Size: 4, Value: uint32(0x80000001)},
+		{Method: "ReadBits", Offset: 6, Size: 1, Bit: 0, Bits: 3, Value: uint(5)},
+		{Method: "ReadBits", Offset: 6, Size: 1, Bit: 3, Bits: 5, Value: uint(0x14)},
+		{Method: "ReadString", Offset: 7, Size: 3, Value: "hi"},
+		{Method: "Inc", Offset: 10, Size: 1, Value: 1},
+		{Method: "ReadBytes", Offset: 11, Size: 2, Value: []byte{1, 2}},
+	}, trace.Entries)
+	assert.Equal(t, strings.Join([]string{
+		`00000000    2 bytes   ReadUInt16    4151`,
+		`00000002    4 bytes   ReadBigSmart  2147483649`,
+		`00000006.0  3 bits    ReadBits      5`,
+		`00000006.3  5 bits    ReadBits      20`,
+		`00000007    3 bytes   ReadString    "hi"`,
+		`0000000a    1 bytes   Inc           1`,
+		`0000000b    2 bytes   ReadBytes     01 02`,
+		``,
+	}, "\n"), trace.String())
+}
+
+func TestReader_TraceNested(t *testing.T) {
+	trace := &Trace{}
+	r := NewTracedReader(NewReader([]byte{0xFF, 0xFF, 0x05, 0x81}), trace)
+	r.ReadIncrSmart()
+	r.ReadUInt8T(TransformA)
+	require.Len(t, trace.Entries, 2)
+	assert.Equal(t, TraceEntry{Method: "ReadIncrSmart", Offset: 0, Size: 3, Value: 32767 + 5}, trace.Entries[0])
+	assert.Equal(t, TraceEntry{Method: "ReadUInt8T", Offset: 3, Size: 1, Value: uint8(1)}, trace.Entries[1])
+}
+
+func TestReader_TraceFailed(t *testing.T) {
+	trace := &Trace{}
+	r := NewTracedReader(NewCheckedReader([]byte{1}), trace)
+	r.ReadUInt16()
+	r.ReadUInt8()
+	assert.Equal(t, []TraceEntry{
+		{Method: "ReadUInt16", Offset: 0, Value: uint16(0), Failed: true},
+		{Method: "ReadUInt8", Offset: 0, Value: uint8(0), Failed: true},
+	}, trace.Entries)
+
+	// The panic of an unchecked Reader continues after it was recorded.
+	trace = &Trace{}
+	r = NewTracedReader(NewReader([]byte{1}), trace)
+	assert.Panics(t, func() { r.ReadUInt16() })
+	require.Len(t, trace.Entries, 1)
+	assert.Equal(t, "ReadUInt16", trace.Entries[0].Method)
+	assert.True(t, trace.Entries[0].Failed)
+}
+
+func TestWriter_Trace(t *testing.T) {
+	for name, writer := range map[string]Writer{
+		"fixed":      NewFixedWriter(16),
+		"expandable": NewExpandableWriter(),
+	} {
+		trace := &Trace{}
+		w := NewTracedWriter(writer, trace)
+		w.WriteUInt16(4151)
+		w.WriteBigSmart(70000)
+		bits := w.BitAccess()
+		bits(3, 5)
+		bits(5, 0x14)
+		w.WriteString("hi", 0)
+		mark := w.ReserveLength(1)
+		w.Write([]byte{1, 2})
+		w.FinishLength(mark)
+
+		assert.Equal(t, []TraceEntry{
+			{Method: "WriteUInt16", Offset: 0, Size: 2, Value: uint16(4151)},
+			{Method: "WriteBigSmart", Offset: 2, Size: 4, Value: uint32(70000)},
+			{Method: "WriteBits", Offset: 6, Size: 1, Bit: 0, Bits: 3, Value: uint(5)},
+			{Method: "WriteBits", Offset: 6, Size: 1, Bit: 3, Bits: 5, Value: uint(0x14)},
+			{Method: "WriteString", Offset: 7, Size: 3, Value: "hi"},
+			{Method: "ReserveLength", Offset: 10, Size: 1, Value: 1},
+			{Method: "Write", Offset: 11, Size: 2, Value: []byte{1, 2}},
+			{Method: "FinishLength", Offset: 13, Size: 0, Value: 2},
+		}, trace.Entries, name)
+	}
+
+	trace := &Trace{}
+	w := NewTracedWriter(NewFixedWriterWithPolicy(1, OverflowError), trace)
+	w.WriteUInt16(1)
+	assert.Equal(t, []TraceEntry{{Method: "WriteUInt16", Value: uint16(1), Failed: true}}, trace.Entries)
+}
+
+func TestTrace_Diff(t *testing.T) {
+	written := &Trace{}
+	w := NewTracedWriter(NewExpandableWriter(), written)
+	w.WriteUInt16(7)
+	w.WriteSmart(300)
+	w.WriteString("name", 10)
+
+	read := &Trace{}
+	r := NewTracedReader(NewReader(w.Payload()), read)
+	r.ReadUInt16()
+	r.ReadSmart()
+	r.ReadString(10)
+	assert.Equal(t, "", read.Diff(written))
+
+	// Reading the smart as a short shifts nothing but changes the entry.
+	misread := &Trace{}
+	r = NewTracedReader(NewReader(w.Payload()), misread)
+	r.ReadUInt16()
+	r.ReadUInt16()
+	assert.Equal(t, strings.Join([]string{
+		"entry 1:",
+		"- 00000002    2 bytes   ReadUInt16  33068",
+		"+ 00000002    2 bytes   WriteSmart  300",
+		"entry 2:",
+		`+ 00000004    5 bytes   WriteString  "name"`,
+		"",
+	}, "\n"), misread.Diff(written))
+}
+
+func TestTrace_Spans(t *testing.T) {
+	data := []byte{0, 1, 0x80, 0xFF, 0xEE}
+	trace := &Trace{}
+	r := NewTracedReader(NewCheckedReader(data), trace)
+	r.ReadUInt16()
+	r.ReadBits()(1)
+	r.SetCurrentRead(3)
+	r.ReadUInt32()
+	assert.Equal(t, []hexdump.Span{
+		{Offset: 0, Size: 2, Name: "ReadUInt16", Type: "uint16", Value: uint16(1)},
+		{Offset: 2, Size: 1, Bits: 1, Name: "ReadBits", Type: "uint", Value: uint(1)},
+		{Offset: 3, Size: 0, Name: "ReadUInt32 (failed)", Type: "uint32", Value: uint32(0)},
+	}, trace.Spans())
+
+	var out bytes.Buffer
+	require.NoError(t, hexdump.Annotate(&out, data, trace.Spans(), hexdump.Options{}))
+	assert.Contains(t, out.String(), "00000000    00 01                                            ReadUInt16           uint16  1\n")
+	assert.Contains(t, out.String(), "00000003    ff ee                                            (unconsumed, 2 bytes)\n")
+}
+
+func TestStreamWriter_Trace(t *testing.T) {
+	var sink bytes.Buffer
+	trace := &Trace{}
+	w := NewTracedWriter(NewStreamWriterWithOrder(&sink, 4, binary.BigEndian), trace)
+	w.WriteUInt16(1)
+	w.WriteUInt32(2)
+	w.Write(make([]byte, 8))
+	w.WriteByte(3)
+	assert.Equal(t, []TraceEntry{
+		{Method: "WriteUInt16", Offset: 0, Size: 2, Value: uint16(1)},
+		{Method: "WriteUInt32", Offset: 2, Size: 4, Value: uint32(2)},
+		{Method: "Write", Offset: 6, Size: 8, Value: make([]byte, 8)},
+		{Method: "WriteByte", Offset: 14, Size: 1, Value: byte(3)},
+	}, trace.Entries)
+}
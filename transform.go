@@ -0,0 +1,155 @@
+package bytepal
+
+// Transform is an obfuscation applied to a single byte, for multi byte values it is applied to the lowest byte.
+type Transform int
+
+const (
+	// TransformNone leaves the byte untouched.
+	TransformNone Transform = iota
+	// TransformA adds 128 to the byte.
+	TransformA
+	// TransformC negates the byte.
+	TransformC
+	// TransformS subtracts the byte from 128.
+	TransformS
+)
+
+// apply obfuscates v for writing.
+func (t Transform) apply(v byte) byte {
+	switch t {
+	case TransformA:
+		return v + 128
+	case TransformC:
+		return -v
+	case TransformS:
+		return 128 - v
+	}
+	return v
+}
+
+// revert restores an obfuscated byte that was read.
+func (t Transform) revert(v byte) byte {
+	switch t {
+	case TransformA:
+		return v - 128
+	case TransformC:
+		return -v
+	case TransformS:
+		return 128 - v
+	}
+	return v
+}
+
+// ReadUInt8T reads a byte obfuscated with the transform t
+func (b *Reader) ReadUInt8T(t Transform) uint8 {
+	return t.revert(b.ReadUInt8())
+}
+
+// ReadInt8T reads a signed byte obfuscated with the transform t
+func (b *Reader) ReadInt8T(t Transform) int8 {
+	return int8(t.revert(b.ReadUInt8()))
+}
+
+// ReadUInt16T reads a short in the byte order of the Reader with the transform t applied to its low byte
+func (b *Reader) ReadUInt16T(t Transform) uint16 {
+	v := b.ReadUInt16()
+	return v&^0xFF | uint16(t.revert(byte(v)))
+}
+
+// ReadInt16T reads a signed short in the byte order of the Reader with the transform t applied to its low byte
+func (b *Reader) ReadInt16T(t Transform) int16 {
+	return int16(b.ReadUInt16T(t))
+}
+
+// ReadLEUInt16T reads a little endian short with the transform t applied to its low byte
+func (b *Reader) ReadLEUInt16T(t Transform) uint16 {
+	v := b.ReadLEUInt16()
+	return v&^0xFF | uint16(t.revert(byte(v)))
+}
+
+// ReadLEInt16T reads a signed little endian short with the transform t applied to its low byte
+func (b *Reader) ReadLEInt16T(t Transform) int16 {
+	return int16(b.ReadLEUInt16T(t))
+}
+
+// ReadUInt32T reads an int in the byte order of the Reader with the transform t applied to its low byte
+func (b *Reader) ReadUInt32T(t Transform) uint32 {
+	v := b.ReadUInt32()
+	return v&^0xFF | uint32(t.revert(byte(v)))
+}
+
+// ReadInt32T reads a signed int in the byte order of the Reader with the transform t applied to its low byte
+func (b *Reader) ReadInt32T(t Transform) int32 {
+	return int32(b.ReadUInt32T(t))
+}
+
+// ReadLEUInt32T reads a little endian int with the transform t applied to its low byte
+func (b *Reader) ReadLEUInt32T(t Transform) uint32 {
+	v := b.ReadLEUInt32()
+	return v&^0xFF | uint32(t.revert(byte(v)))
+}
+
+// ReadLEInt32T reads a signed little endian int with the transform t applied to its low byte
+func (b *Reader) ReadLEInt32T(t Transform) int32 {
+	return int32(b.ReadLEUInt32T(t))
+}
+
+// WriteUInt8T writes a byte obfuscated with the transform t
+func (a *FixedWriter) WriteUInt8T(v uint8, t Transform) {
+	a.WriteUInt8(t.apply(v))
+}
+
+// WriteInt8T writes a signed byte obfuscated with the transform t
+func (a *FixedWriter) WriteInt8T(v int8, t Transform) {
+	a.WriteUInt8(t.apply(uint8(v)))
+}
+
+// WriteInt16T writes a short in the byte order of the Writer with the transform t applied to its low byte
+func (a *FixedWriter) WriteInt16T(v int16, t Transform) {
+	a.WriteInt16(v&^0xFF | int16(t.apply(byte(v))))
+}
+
+// WriteLEInt16T writes a little endian short with the transform t applied to its low byte
+func (a *FixedWriter) WriteLEInt16T(v int16, t Transform) {
+	a.WriteLEInt16(v&^0xFF | int16(t.apply(byte(v))))
+}
+
+// WriteInt32T writes an int in the byte order of the Writer with the transform t applied to its low byte
+func (a *FixedWriter) WriteInt32T(v int32, t Transform) {
+	a.WriteInt32(v&^0xFF | int32(t.apply(byte(v))))
+}
+
+// WriteLEInt32T writes a little endian int with the transform t applied to its low byte
+func (a *FixedWriter) WriteLEInt32T(v int32, t Transform) {
+	a.WriteLEInt32(v&^0xFF | int32(t.apply(byte(v))))
+}
+
+// WriteUInt8T writes a byte obfuscated with the transform t
+func (a *ExpandableWriter) WriteUInt8T(v uint8, t Transform) {
+	a.WriteUInt8(t.apply(v))
+}
+
+// WriteInt8T writes a signed byte obfuscated with the transform t
+func (a *ExpandableWriter) WriteInt8T(v int8, t Transform) {
+	a.WriteUInt8(t.apply(uint8(v)))
+}
+
+// WriteInt16T writes a short in the byte order of the Writer with the transform t applied to its low byte
+func (a *ExpandableWriter) WriteInt16T(v int16, t Transform) {
+	a.WriteInt16(v&^0xFF | int16(t.apply(byte(v))))
+}
+
+// WriteLEInt16T writes a little endian short with the transform t applied to its low byte
+func (a *ExpandableWriter) WriteLEInt16T(v int16, t Transform) {
+	a.WriteLEInt16(v&^0xFF | int16(t.apply(byte(v))))
+}
+
+// WriteInt32T writes an int in the byte order of the Writer with the transform t applied to its low byte
+func (a *ExpandableWriter) WriteInt32T(v int32, t Transform) {
+	a.WriteInt32(v&^0xFF | int32(t.apply(byte(v))))
+}
+
+// WriteLEInt32T writes a little endian int with the transform t applied to its low byte
+func (a *ExpandableWriter) WriteLEInt32T(v int32, t Transform) {
+	a.WriteLEInt32(v&^0xFF | int32(t.apply(byte(v))))
+}
@@ -0,0 +1,57 @@
+package bytepal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testTransforms(t *testing.T, out Writer) {
+	for _, tr := range []Transform{TransformNone, TransformA, TransformC, TransformS} {
+		out.WriteUInt8T(200, tr)
+		out.WriteInt8T(-128, tr)
+		out.WriteInt16T(-1234, tr)
+		out.WriteLEInt16T(-1234, tr)
+		out.WriteInt32T(-123456789, tr)
+		out.WriteLEInt32T(123456789, tr)
+		out.WriteLEInt32T(-123456789, tr)
+	}
+	require.NoError(t, out.Err())
+
+	in := NewCheckedReader(out.Payload())
+	for _, tr := range []Transform{TransformNone, TransformA, TransformC, TransformS} {
+		assert.Equal(t, uint8(200), in.ReadUInt8T(tr))
+		assert.Equal(t, int8(-128), in.ReadInt8T(tr))
+		assert.Equal(t, int16(-1234), in.ReadInt16T(tr))
+		assert.Equal(t, int16(-1234), in.ReadLEInt16T(tr))
+		assert.Equal(t, int32(-123456789), in.ReadInt32T(tr))
+		assert.Equal(t, uint32(123456789), in.ReadLEUInt32T(tr))
+		assert.Equal(t, int32(-123456789), in.ReadLEInt32T(tr))
+	}
+	assert.NoError(t, in.Err())
+	assert.Equal(t, 0, in.Remaining())
+}
+
+func TestFixedWriter_Transforms(t *testing.T) {
+	testTransforms(t, NewFixedWriter(4*18))
+}
+func TestExpandableWriter_Transforms(t *testing.T) {
+	testTransforms(t, NewExpandableWriter())
+}
+
+func TestWriter_TransformEncodings(t *testing.T) {
+	out := NewExpandableWriter()
+	out.WriteUInt8T(1, TransformA)
+	out.WriteUInt8T(1, TransformC)
+	out.WriteUInt8T(1, TransformS)
+	out.WriteInt16T(0x1234, TransformA)
+	out.WriteLEInt16T(0x1234, TransformA)
+	assert.Equal(t, []byte{0x81, 0xFF, 0x7F, 0x12, 0xB4, 0xB4, 0x12}, out.Payload())
+
+	in := NewReader(out.Payload())
+	assert.Equal(t, uint8(1), in.ReadUInt8T(TransformA))
+	assert.Equal(t, uint8(1), in.ReadUInt8T(TransformC))
+	assert.Equal(t, uint8(1), in.ReadUInt8T(TransformS))
+	assert.Equal(t, uint16(0x1234), in.ReadUInt16T(TransformA))
+	assert.Equal(t, uint16(0x1234), in.ReadLEUInt16T(TransformA))
+}
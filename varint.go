@@ -0,0 +1,311 @@
+package bytepal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+const (
+	// MaxVarintLen32 is the maximum length of a varint holding a 32 bit value.
+	MaxVarintLen32 = 5
+	// MaxVarintLen64 is the maximum length of a varint holding a 64 bit value.
+	MaxVarintLen64 = 10
+)
+
+const (
+	// MaxQUICVarint is the largest value that can be written as a QUIC varint.
+	MaxQUICVarint = 1<<62 - 1
+)
+
+// ErrVarintOverflow is recorded when a varint is longer than the value it is read into allows.
+var ErrVarintOverflow = errors.New("bytepal: varint overflows")
+
+// ErrNonCanonical is recorded by a Reader requiring canonical encodings when a varint uses more bytes than needed.
+var ErrNonCanonical = errors.New("bytepal: non-canonical varint")
+
+// SetCanonical makes every varint read fail with ErrNonCanonical if it was not encoded in the fewest bytes possible.
+func (b *Reader) SetCanonical(canonical bool) {
+	b.canonical = canonical
+}
+
+// nonCanonical records an ErrNonCanonical for the varint that started at offset.
+func (b *Reader) nonCanonical(name string, offset int) {
+	b.fail(fmt.Errorf("%w: %s at offset %d", ErrNonCanonical, name, offset))
+}
+
+// uvarint decodes an unsigned LEB128 value of at most width bits straight off the payload.
+func (b *Reader) uvarint(width uint) uint64 {
+	if b.err != nil {
+		return 0
+	}
+	var v uint64
+	var shift uint
+	for i := b.currentIndex; i < b.limit; i++ {
+		c := b.bytes[i]
+		if shift+7 >= width && c>>(width-shift) != 0 {
+			b.fail(fmt.Errorf("%w: %d bit varint at offset %d", ErrVarintOverflow, width, b.currentIndex))
+			return 0
+		}
+		v |= uint64(c&0x7F) << shift
+		if c < 0x80 {
+			if b.canonical && c == 0 && i > b.currentIndex {
+				b.nonCanonical("LEB128", b.currentIndex)
+				return 0
+			}
+			b.currentIndex = i + 1
+			return v
+		}
+		shift += 7
+	}
+	b.fail(&ReadError{Offset: b.currentIndex, Size: b.Remaining() + 1, Available: b.Remaining()})
+	return 0
+}
+
+// ReadUVarint reads an unsigned LEB128 (protobuf) varint of up to 64 bits.
+func (b *Reader) ReadUVarint() uint64 {
+	return b.uvarint(64)
+}
+
+// ReadUVarint32 reads an unsigned LEB128 varint, values that do not fit 32 bits are an error.
+func (b *Reader) ReadUVarint32() uint32 {
+	return uint32(b.uvarint(32))
+}
+
+// ReadVarint reads a zigzag encoded signed varint of up to 64 bits.
+func (b *Reader) ReadVarint() int64 {
+	v := b.uvarint(64)
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// ReadVarint32 reads a zigzag encoded signed varint, values that do not fit 32 bits are an error.
+func (b *Reader) ReadVarint32() int32 {
+	v := uint32(b.uvarint(32))
+	return int32(v>>1) ^ -int32(v&1)
+}
+
+// ReadQUICVarint reads a QUIC (RFC 9000) varint, the top two bits of the first byte give its length of 1, 2, 4 or 8 bytes.
+func (b *Reader) ReadQUICVarint() uint64 {
+	offset := b.currentIndex
+	size := 1 << (b.peek() >> 6)
+	data := b.take(size)
+	v := uint64(data[0] & 0x3F)
+	for _, c := range data[1:] {
+		v = v<<8 | uint64(c)
+	}
+	if b.canonical && b.err == nil && size > 1 && quicVarintLen(v) != size {
+		b.nonCanonical("QUIC varint", offset)
+		return 0
+	}
+	return v
+}
+
+// ReadSQLiteVarint reads a big endian SQLite varint of 1 to 9 bytes, the ninth byte contributes all 8 of its bits.
+func (b *Reader) ReadSQLiteVarint() uint64 {
+	offset := b.currentIndex
+	var v uint64
+	c, size := byte(0x80), 0
+	for ; size < 8 && c >= 0x80; size++ {
+		c = b.ReadUInt8()
+		v = v<<7 | uint64(c&0x7F)
+	}
+	if c >= 0x80 {
+		v = v<<8 | uint64(b.ReadUInt8())
+		size++
+	}
+	if b.err != nil {
+		return 0
+	}
+	if b.canonical && size != sqliteVarintLen(v) {
+		b.nonCanonical("SQLite varint", offset)
+		return 0
+	}
+	return v
+}
+
+// ReadCompactSize reads a Bitcoin CompactSize, values from 0xFD up follow a 0xFD, 0xFE or 0xFF marker
+//	as a little endian short, int or long.
+func (b *Reader) ReadCompactSize() uint64 {
+	offset := b.currentIndex
+	var v, min uint64
+	switch marker := b.ReadUInt8(); marker {
+	case 0xFD:
+		v, min = uint64(b.ReadLEUInt16()), 0xFD
+	case 0xFE:
+		v, min = uint64(b.ReadLEUInt32()), 1<<16
+	case 0xFF:
+		v, min = b.ReadLEUInt64(), 1<<32
+	default:
+		return uint64(marker)
+	}
+	if b.canonical && b.err == nil && v < min {
+		b.nonCanonical("CompactSize", offset)
+		return 0
+	}
+	return v
+}
+
+// uvarintLen returns the number of bytes needed to encode v.
+func uvarintLen(v uint64) int {
+	n := 1
+	for ; v >= 0x80; v >>= 7 {
+		n++
+	}
+	return n
+}
+
+// putUVarint encodes v into data which must be uvarintLen(v) bytes long.
+func putUVarint(data []byte, v uint64) {
+	i := 0
+	for ; v >= 0x80; v >>= 7 {
+		data[i] = byte(v) | 0x80
+		i++
+	}
+	data[i] = byte(v)
+}
+
+// zigzag maps signed values onto unsigned ones so small negative numbers stay short.
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// quicVarintLen returns the number of bytes needed to encode v as a QUIC varint.
+func quicVarintLen(v uint64) int {
+	switch {
+	case v < 1<<6:
+		return 1
+	case v < 1<<14:
+		return 2
+	case v < 1<<30:
+		return 4
+	}
+	return 8
+}
+
+// putQUICVarint encodes v into data which must be quicVarintLen(v) bytes long.
+func putQUICVarint(data []byte, v uint64) {
+	for i := len(data) - 1; i >= 0; i-- {
+		data[i] = byte(v)
+		v >>= 8
+	}
+	data[0] |= byte(bits.TrailingZeros(uint(len(data)))) << 6
+}
+
+// sqliteVarintLen returns the number of bytes needed to encode v as a SQLite varint.
+func sqliteVarintLen(v uint64) int {
+	if v >= 1<<56 {
+		return 9
+	}
+	n := 1
+	for ; v >= 0x80; v >>= 7 {
+		n++
+	}
+	return n
+}
+
+// putSQLiteVarint encodes v into data which must be sqliteVarintLen(v) bytes long.
+func putSQLiteVarint(data []byte, v uint64) {
+	i := len(data) - 1
+	if len(data) == 9 {
+		data[i] = byte(v)
+		v >>= 8
+		i--
+	} else {
+		data[i] = byte(v) & 0x7F
+		v >>= 7
+		i--
+	}
+	for ; i >= 0; i-- {
+		data[i] = byte(v) | 0x80
+		v >>= 7
+	}
+}
+
+// compactSizeLen returns the number of bytes needed to encode v as a CompactSize.
+func compactSizeLen(v uint64) int {
+	switch {
+	case v < 0xFD:
+		return 1
+	case v <= 0xFFFF:
+		return 3
+	case v <= 0xFFFFFFFF:
+		return 5
+	}
+	return 9
+}
+
+// putCompactSize encodes v into data which must be compactSizeLen(v) bytes long.
+func putCompactSize(data []byte, v uint64) {
+	switch len(data) {
+	case 1:
+		data[0] = byte(v)
+	case 3:
+		data[0] = 0xFD
+		binary.LittleEndian.PutUint16(data[1:], uint16(v))
+	case 5:
+		data[0] = 0xFE
+		binary.LittleEndian.PutUint32(data[1:], uint32(v))
+	default:
+		data[0] = 0xFF
+		binary.LittleEndian.PutUint64(data[1:], v)
+	}
+}
+
+// WriteUVarint writes v as an unsigned LEB128 (protobuf) varint
+func (a *FixedWriter) WriteUVarint(v uint64) {
+	putUVarint(a.reserve(uvarintLen(v)), v)
+}
+
+// WriteVarint writes v as a zigzag encoded signed varint
+func (a *FixedWriter) WriteVarint(v int64) {
+	a.WriteUVarint(zigzag(v))
+}
+
+// WriteQUICVarint writes v as a QUIC varint, values above MaxQUICVarint are an error.
+func (a *FixedWriter) WriteQUICVarint(v uint64) {
+	if v > MaxQUICVarint {
+		a.fail(fmt.Errorf("%w: QUIC varint %d", ErrRange, v))
+		return
+	}
+	putQUICVarint(a.reserve(quicVarintLen(v)), v)
+}
+
+// WriteSQLiteVarint writes v as a big endian SQLite varint
+func (a *FixedWriter) WriteSQLiteVarint(v uint64) {
+	putSQLiteVarint(a.reserve(sqliteVarintLen(v)), v)
+}
+
+// WriteCompactSize writes v as a Bitcoin CompactSize
+func (a *FixedWriter) WriteCompactSize(v uint64) {
+	putCompactSize(a.reserve(compactSizeLen(v)), v)
+}
+
+// WriteUVarint writes v as an unsigned LEB128 (protobuf) varint
+func (a *ExpandableWriter) WriteUVarint(v uint64) {
+	putUVarint(a.reserve(uvarintLen(v)), v)
+}
+
+// WriteVarint writes v as a zigzag encoded signed varint
+func (a *ExpandableWriter) WriteVarint(v int64) {
+	a.WriteUVarint(zigzag(v))
+}
+
+// WriteQUICVarint writes v as a QUIC varint, values above MaxQUICVarint are an error.
+func (a *ExpandableWriter) WriteQUICVarint(v uint64) {
+	if v > MaxQUICVarint {
+		a.fail(fmt.Errorf("%w: QUIC varint %d", ErrRange, v))
+		return
+	}
+	putQUICVarint(a.reserve(quicVarintLen(v)), v)
+}
+
+// WriteSQLiteVarint writes v as a big endian SQLite varint
+func (a *ExpandableWriter) WriteSQLiteVarint(v uint64) {
+	putSQLiteVarint(a.reserve(sqliteVarintLen(v)), v)
+}
+
+// WriteCompactSize writes v as a Bitcoin CompactSize
+func (a *ExpandableWriter) WriteCompactSize(v uint64) {
+	putCompactSize(a.reserve(compactSizeLen(v)), v)
+}
@@ -0,0 +1,165 @@
+package bytepal
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math"
+	"testing"
+)
+
+var uvarints = []uint64{0, 1, 127, 128, 300, 16383, 16384, math.MaxUint32, math.MaxUint32 + 1, math.MaxUint64}
+var varints = []int64{0, -1, 1, -64, 64, math.MinInt32, math.MaxInt32, math.MinInt64, math.MaxInt64}
+
+func testVarints(t *testing.T, out Writer) {
+	for _, v := range uvarints {
+		out.WriteUVarint(v)
+	}
+	for _, v := range varints {
+		out.WriteVarint(v)
+	}
+	require.NoError(t, out.Err())
+
+	// The encoding must match encoding/binary
+	expected := make([]byte, 0)
+	buf := make([]byte, binary.MaxVarintLen64)
+	for _, v := range uvarints {
+		expected = append(expected, buf[:binary.PutUvarint(buf, v)]...)
+	}
+	for _, v := range varints {
+		expected = append(expected, buf[:binary.PutVarint(buf, v)]...)
+	}
+	assert.Equal(t, expected, out.Payload())
+
+	in := NewCheckedReader(out.Payload())
+	for _, v := range uvarints {
+		assert.Equal(t, v, in.ReadUVarint())
+	}
+	for _, v := range varints {
+		assert.Equal(t, v, in.ReadVarint())
+	}
+	assert.NoError(t, in.Err())
+	assert.Equal(t, 0, in.Remaining())
+}
+
+func TestFixedWriter_Varints(t *testing.T) {
+	testVarints(t, NewFixedWriterWithPolicy(0, OverflowGrow))
+}
+func TestExpandableWriter_Varints(t *testing.T) {
+	testVarints(t, NewExpandableWriter())
+}
+
+func TestReader_ReadVarint32(t *testing.T) {
+	out := NewExpandableWriter()
+	out.WriteUVarint(math.MaxUint32)
+	out.WriteVarint(math.MinInt32)
+	out.WriteVarint(math.MaxInt32)
+
+	in := NewCheckedReader(out.Payload())
+	assert.Equal(t, uint32(math.MaxUint32), in.ReadUVarint32())
+	assert.Equal(t, int32(math.MinInt32), in.ReadVarint32())
+	assert.Equal(t, int32(math.MaxInt32), in.ReadVarint32())
+	assert.NoError(t, in.Err())
+}
+
+func TestReader_ReadUVarintOverflow(t *testing.T) {
+	tests := map[string]func(r *Reader){
+		"ReadUVarint":   func(r *Reader) { r.ReadUVarint() },
+		"ReadUVarint32": func(r *Reader) { r.ReadUVarint32() },
+	}
+	payloads := map[string][]byte{
+		"ReadUVarint":   {0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x02},
+		"ReadUVarint32": {0xFF, 0xFF, 0xFF, 0xFF, 0x10},
+	}
+	for name, read := range tests {
+		in := NewCheckedReader(payloads[name])
+		read(in)
+		assert.True(t, errors.Is(in.Err(), ErrVarintOverflow), name)
+	}
+
+	in := NewCheckedReader([]byte{0x80, 0x80})
+	assert.Equal(t, uint64(0), in.ReadUVarint())
+	assert.True(t, errors.Is(in.Err(), io.ErrUnexpectedEOF))
+}
+
+func testPrefixVarints(t *testing.T, out Writer) {
+	values := []uint64{0, 63, 64, 0xFC, 0xFD, 16383, 16384, 0xFFFF, 0x10000, 1<<30 - 1, 1 << 30, 0xFFFFFFFF, 1 << 32, 1<<56 - 1, 1 << 56, MaxQUICVarint}
+	for _, v := range values {
+		out.WriteQUICVarint(v)
+		out.WriteSQLiteVarint(v)
+		out.WriteCompactSize(v)
+	}
+	out.WriteSQLiteVarint(math.MaxUint64)
+	out.WriteCompactSize(math.MaxUint64)
+	require.NoError(t, out.Err())
+
+	in := NewCheckedReader(out.Payload())
+	in.SetCanonical(true)
+	for _, v := range values {
+		assert.Equal(t, v, in.ReadQUICVarint())
+		assert.Equal(t, v, in.ReadSQLiteVarint())
+		assert.Equal(t, v, in.ReadCompactSize())
+	}
+	assert.Equal(t, uint64(math.MaxUint64), in.ReadSQLiteVarint())
+	assert.Equal(t, uint64(math.MaxUint64), in.ReadCompactSize())
+	assert.NoError(t, in.Err())
+	assert.Equal(t, 0, in.Remaining())
+}
+
+func TestFixedWriter_PrefixVarints(t *testing.T) {
+	testPrefixVarints(t, NewFixedWriterWithPolicy(0, OverflowGrow))
+}
+func TestExpandableWriter_PrefixVarints(t *testing.T) {
+	testPrefixVarints(t, NewExpandableWriter())
+}
+
+func TestWriter_PrefixVarintEncodings(t *testing.T) {
+	out := NewExpandableWriter()
+	// Examples from RFC 9000 section A.1
+	out.WriteQUICVarint(151288809941952652)
+	out.WriteQUICVarint(494878333)
+	out.WriteQUICVarint(15293)
+	out.WriteQUICVarint(37)
+	assert.Equal(t, []byte{
+		0xc2, 0x19, 0x7c, 0x5e, 0xff, 0x14, 0xe8, 0x8c,
+		0x9d, 0x7f, 0x3e, 0x7d,
+		0x7b, 0xbd,
+		0x25,
+	}, out.Payload())
+
+	out = NewExpandableWriter()
+	out.WriteSQLiteVarint(240)
+	out.WriteSQLiteVarint(1 << 56)
+	out.WriteCompactSize(0xFD)
+	assert.Equal(t, []byte{
+		0x81, 0x70,
+		0x80, 0xC0, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00,
+		0xFD, 0xFD, 0x00,
+	}, out.Payload())
+
+	out = NewExpandableWriter()
+	out.WriteQUICVarint(MaxQUICVarint + 1)
+	assert.True(t, errors.Is(out.Err(), ErrRange))
+}
+
+func TestReader_NonCanonical(t *testing.T) {
+	tests := map[string]struct {
+		payload []byte
+		read    func(r *Reader) uint64
+	}{
+		"LEB128":      {[]byte{0x81, 0x00}, func(r *Reader) uint64 { return r.ReadUVarint() }},
+		"QUIC":        {[]byte{0x40, 0x01}, func(r *Reader) uint64 { return r.ReadQUICVarint() }},
+		"SQLite":      {[]byte{0x80, 0x01}, func(r *Reader) uint64 { return r.ReadSQLiteVarint() }},
+		"CompactSize": {[]byte{0xFD, 0x01, 0x00}, func(r *Reader) uint64 { return r.ReadCompactSize() }},
+	}
+	for name, test := range tests {
+		assert.Equal(t, uint64(1), test.read(NewReader(test.payload)), name)
+
+		in := NewCheckedReader(test.payload)
+		in.SetCanonical(true)
+		assert.Equal(t, uint64(0), test.read(in), name)
+		assert.True(t, errors.Is(in.Err(), ErrNonCanonical), name)
+	}
+}
@@ -1,13 +1,45 @@
 package bytepal
 
 import (
+	"encoding/binary"
+	"fmt"
+	"io"
 	"math"
 )
 
+// OverflowPolicy decides what a FixedWriter does with a write that does not fit its buffer.
+type OverflowPolicy int
+
+const (
+	// OverflowPanic panics with a WriteError, this is the default policy.
+	OverflowPanic OverflowPolicy = iota
+	// OverflowError drops the write and records a WriteError, every write after it is dropped too.
+	OverflowError
+	// OverflowDrop silently drops any write that does not fit.
+	OverflowDrop
+	// OverflowGrow expands the buffer like an ExpandableWriter.
+	OverflowGrow
+)
+
+// WriteError is recorded when a write does not fit in the buffer of a FixedWriter.
+type WriteError struct {
+	Offset    int
+	Size      int
+	Available int
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("bytepal: write of %d bytes at offset %d, only %d available", e.Size, e.Offset, e.Available)
+}
+
 // bitWriter is the underlying base structure of this writer, shares all common characteristics as a bit is the lowest.
 type bitWriter struct {
 	bytes        []byte
 	currentIndex int
+	order        binary.ByteOrder
+	// bigEndian is set for binary.BigEndian, whose writes skip the call through order.
+	bigEndian bool
+	err       error
 }
 
 // SetCurrentWrite moves the current index that will be written.
@@ -20,22 +52,85 @@ func (w *bitWriter) Size() int {
 	return len(w.bytes)
 }
 
+// position is the offset of the next byte written.
+func (w *bitWriter) position() int {
+	return w.currentIndex
+}
+
+// written is the number of bytes after the placeholder of mark.
+func (w *bitWriter) written(mark LengthMark) int {
+	return w.currentIndex - mark.start
+}
+
 // Payload returns the byte buffer inside the FixedWriter
 func (a *bitWriter) Payload() []byte {
 	return a.bytes
 }
 
+// Order returns the byte order used by WriteInt16, WriteInt32 and WriteInt64.
+func (a *bitWriter) Order() binary.ByteOrder {
+	return a.order
+}
+
+// putUint16 encodes v in the byte order of the Writer, big endian skips the call through the interface.
+func (a *bitWriter) putUint16(data []byte, v uint16) {
+	if a.bigEndian {
+		binary.BigEndian.PutUint16(data, v)
+		return
+	}
+	a.order.PutUint16(data, v)
+}
+
+// putUint32 encodes v in the byte order of the Writer.
+func (a *bitWriter) putUint32(data []byte, v uint32) {
+	if a.bigEndian {
+		binary.BigEndian.PutUint32(data, v)
+		return
+	}
+	a.order.PutUint32(data, v)
+}
+
+// putUint64 encodes v in the byte order of the Writer.
+func (a *bitWriter) putUint64(data []byte, v uint64) {
+	if a.bigEndian {
+		binary.BigEndian.PutUint64(data, v)
+		return
+	}
+	a.order.PutUint64(data, v)
+}
+
+// Err returns the first error encountered while writing, or nil.
+func (a *bitWriter) Err() error {
+	return a.err
+}
+
+// fail records err unless it is nil or an earlier error was already recorded.
+func (a *bitWriter) fail(err error) {
+	if a.err == nil {
+		a.err = err
+	}
+}
+
 // BitAccess allows writing bits to the array, expands the size if capacity is exceeded.
 //	If a normal write is executed, a new BitAccess must be called to get the appropriate bitPosition.
 func (a *bitWriter) BitAccess() func(uint, uint) {
+	return a.bitAccess(nil)
+}
+
+// bitAccess returns the bit writer of BitAccess. fit decides whether the bytes from start to end may be
+//	written, a nil fit always expands the buffer.
+func (a *bitWriter) bitAccess(fit func(start, end int) bool) func(uint, uint) {
 	bitPosition := uint(a.currentIndex * 8)
 	return func(numBits, value uint) {
 		bytePos := bitPosition >> 3
 		bitOffset := 8 - (bitPosition & 7)
 
-		if availBits := uint(len(a.bytes)*8) - bitPosition; numBits > availBits {
-			addSize := make([]byte, int(math.Ceil(float64(numBits-availBits)/8)))
-			a.bytes = append(a.bytes, addSize...)
+		end := int(bitPosition+numBits+7) / 8
+		if fit != nil && !fit(int(bytePos), end) {
+			return
+		}
+		if end > len(a.bytes) {
+			a.bytes = append(a.bytes, make([]byte, end-len(a.bytes))...)
 		}
 		bitPosition += numBits
 
@@ -61,16 +156,55 @@ type Writer interface {
 	Size() int
 	Payload() []byte
 	BitAccess() func(uint, uint)
+	Order() binary.ByteOrder
+	Err() error
 
-	Write([]uint8)
+	Write([]byte) (int, error)
+	WriteByte(byte) error
 	WriteUInt8(uint8)
+	WriteInt8(int8)
 	WriteInt16(int16)
+	WriteUInt16(uint16)
 	WriteLEInt16(int16)
+	WriteLEUInt16(uint16)
+	WriteMedium(int32)
+	WriteLEMedium(int32)
 	WriteInt32(int32)
+	WriteUInt32(uint32)
 	WriteLEInt32(int32)
+	WriteLEUInt32(uint32)
+	WriteMEInt32(int32)
+	WriteMEUInt32(uint32)
+	WriteIMEInt32(int32)
+	WriteIMEUInt32(uint32)
 	WriteInt64(int64)
+	WriteUInt64(uint64)
 	WriteLEInt64(int64)
+	WriteLEUInt64(uint64)
+	WriteFloat32(float32)
+	WriteLEFloat32(float32)
+	WriteFloat64(float64)
+	WriteLEFloat64(float64)
 	WriteString(string, byte)
+	WriteSmart(uint16)
+	WriteSignedSmart(int16)
+	WriteBigSmart(uint32)
+	WriteNullableBigSmart(int32)
+	WriteIncrSmart(int)
+	WriteUInt8T(uint8, Transform)
+	WriteInt8T(int8, Transform)
+	WriteInt16T(int16, Transform)
+	WriteLEInt16T(int16, Transform)
+	WriteInt32T(int32, Transform)
+	WriteLEInt32T(int32, Transform)
+	WriteUVarint(uint64)
+	WriteVarint(int64)
+	WriteQUICVarint(uint64)
+	WriteSQLiteVarint(uint64)
+	WriteCompactSize(uint64)
+	WriteOpcode(Cipher, uint8)
+	ReserveLength(int) LengthMark
+	FinishLength(LengthMark)
 }
 
 // FixedWriter represents a fixed buffer size with functions to write data to its buffer
@@ -78,94 +212,247 @@ var _ Writer = &FixedWriter{}
 
 type FixedWriter struct {
 	*bitWriter
+	policy  OverflowPolicy
+	scratch [8]byte
 }
 
 // NewFixedWriter allocates an Array with a fixed size and values already initialized.
+//	Writes that do not fit the buffer panic, see NewFixedWriterWithPolicy to change this.
 //	NOTE: See benchmarks in terms of comparison between FixedWriter & ExpandableWriter to determine which you want.
 func NewFixedWriter(size int) Writer {
+	return NewFixedWriterWithPolicy(size, OverflowPanic)
+}
+
+// NewFixedWriterWithPolicy allocates a FixedWriter that handles writes past its size with the given policy.
+func NewFixedWriterWithPolicy(size int, policy OverflowPolicy) Writer {
+	return NewFixedWriterWithOrderAndPolicy(size, binary.BigEndian, policy)
+}
+
+// NewFixedWriterWithOrder allocates a FixedWriter where WriteInt16, WriteInt32, WriteInt64 and their unsigned
+//	and float variants follow the given byte order.
+func NewFixedWriterWithOrder(size int, order binary.ByteOrder) Writer {
+	return NewFixedWriterWithOrderAndPolicy(size, order, OverflowPanic)
+}
+
+// NewFixedWriterWithOrderAndPolicy allocates a FixedWriter with both the byte order of NewFixedWriterWithOrder
+//	and the overflow policy of NewFixedWriterWithPolicy.
+func NewFixedWriterWithOrderAndPolicy(size int, order binary.ByteOrder, policy OverflowPolicy) Writer {
 	return &FixedWriter{
-		&bitWriter{
-			bytes: make([]byte, size),
+		bitWriter: &bitWriter{
+			bytes:     make([]byte, size),
+			order:     order,
+			bigEndian: order == binary.BigEndian,
 		},
+		policy: policy,
+	}
+}
+
+// BitAccess allows writing bits to the buffer. Bits past its size follow the overflow policy, except that
+//	OverflowPanic expands the buffer like OverflowGrow as bit writes always have.
+//	If a normal write is executed, a new BitAccess must be called to get the appropriate bitPosition.
+func (a *FixedWriter) BitAccess() func(uint, uint) {
+	return a.bitAccess(a.fitBits)
+}
+
+// fitBits reports whether a bit write may change the bytes from start to end.
+func (a *FixedWriter) fitBits(start, end int) bool {
+	if a.err != nil {
+		return false
+	}
+	switch {
+	case end <= len(a.bytes), a.policy == OverflowGrow, a.policy == OverflowPanic:
+		return true
+	case a.policy == OverflowError:
+		a.err = &WriteError{Offset: start, Size: end - start, Available: len(a.bytes) - start}
+	}
+	return false
+}
+
+// reserve advances the index pointer by size and returns the bytes to be written.
+//	Writes that are dropped by the overflow policy are given a scratch buffer instead.
+func (a *FixedWriter) reserve(size int) []byte {
+	end := a.currentIndex + size
+	if a.err == nil && end <= len(a.bytes) {
+		a.currentIndex = end
+		return a.bytes[end-size : end]
+	}
+	if a.err == nil {
+		switch a.policy {
+		case OverflowGrow:
+			a.bytes = append(a.bytes, make([]byte, end-len(a.bytes))...)
+			a.currentIndex = end
+			return a.bytes[end-size : end]
+		case OverflowError:
+			a.err = &WriteError{Offset: a.currentIndex, Size: size, Available: len(a.bytes) - a.currentIndex}
+		case OverflowPanic:
+			panic(&WriteError{Offset: a.currentIndex, Size: size, Available: len(a.bytes) - a.currentIndex})
+		}
+	}
+	if size <= len(a.scratch) {
+		return a.scratch[:size]
 	}
+	return make([]byte, size)
 }
 
 // Writes a byte onto the buffer
 func (a *FixedWriter) WriteUInt8(v uint8) {
-	a.bytes[a.currentIndex] = v
-	a.currentIndex++
+	a.reserve(1)[0] = v
 }
 
-// WriteUInt16 writes two bytes to the buffer
+// WriteInt16 writes two bytes to the buffer in the byte order of the Writer
 func (a *FixedWriter) WriteInt16(v int16) {
-	a.bytes[a.currentIndex] = byte(v >> 8)
-	a.bytes[a.currentIndex+1] = byte(v)
-	a.currentIndex += 2
+	a.putUint16(a.reserve(2), uint16(v))
 }
 
 // WriteInt16 writes two bytes in little endian to the buffer
 func (a *FixedWriter) WriteLEInt16(v int16) {
-	a.bytes[a.currentIndex+1] = byte(v >> 8)
-	a.bytes[a.currentIndex] = byte(v)
-	a.currentIndex += 2
+	data := a.reserve(2)
+	data[1] = byte(v >> 8)
+	data[0] = byte(v)
+}
+
+// WriteMedium writes the lower 24 bits of v in big endian order
+func (a *FixedWriter) WriteMedium(v int32) {
+	data := a.reserve(3)
+	data[0] = byte(v >> 16)
+	data[1] = byte(v >> 8)
+	data[2] = byte(v)
+}
+
+// WriteLEMedium writes the lower 24 bits of v in little endian order
+func (a *FixedWriter) WriteLEMedium(v int32) {
+	data := a.reserve(3)
+	data[2] = byte(v >> 16)
+	data[1] = byte(v >> 8)
+	data[0] = byte(v)
 }
 
 // WriteLEInt32 writes a int32 to the buffer in little Endian order
 func (a *FixedWriter) WriteLEInt32(v int32) {
-	a.bytes[a.currentIndex+3] = byte(v >> 24)
-	a.bytes[a.currentIndex+2] = byte(v >> 16)
-	a.bytes[a.currentIndex+1] = byte(v >> 8)
-	a.bytes[a.currentIndex] = byte(v)
-	a.currentIndex += 4
+	data := a.reserve(4)
+	data[3] = byte(v >> 24)
+	data[2] = byte(v >> 16)
+	data[1] = byte(v >> 8)
+	data[0] = byte(v)
 }
 
-// WriteInt32 writes a int32 to the buffer in Big Endian order
+// WriteInt32 writes a int32 to the buffer in the byte order of the Writer
 func (a *FixedWriter) WriteInt32(v int32) {
-	a.bytes[a.currentIndex+3] = byte(v)
-	a.bytes[a.currentIndex+2] = byte(v >> 8)
-	a.bytes[a.currentIndex+1] = byte(v >> 16)
-	a.bytes[a.currentIndex] = byte(v >> 24)
-	a.currentIndex += 4
+	a.putUint32(a.reserve(4), uint32(v))
+}
+
+// WriteMEInt32 writes a int32 to the buffer in middle endian order, 0x0A0B0C0D is written as 0B 0A 0D 0C
+func (a *FixedWriter) WriteMEInt32(v int32) {
+	MiddleEndian.PutUint32(a.reserve(4), uint32(v))
+}
+
+// WriteIMEInt32 writes a int32 to the buffer in inverse middle endian order, 0x0A0B0C0D is written as 0C 0D 0A 0B
+func (a *FixedWriter) WriteIMEInt32(v int32) {
+	InverseMiddleEndian.PutUint32(a.reserve(4), uint32(v))
 }
 
 // WriteLEInt64 writes a int64 to the buffer in little Endian order
 func (a *FixedWriter) WriteLEInt64(v int64) {
-	a.bytes[a.currentIndex+7] = byte(v >> 56)
-	a.bytes[a.currentIndex+6] = byte(v >> 48)
-	a.bytes[a.currentIndex+5] = byte(v >> 40)
-	a.bytes[a.currentIndex+4] = byte(v >> 32)
-	a.bytes[a.currentIndex+3] = byte(v >> 24)
-	a.bytes[a.currentIndex+2] = byte(v >> 16)
-	a.bytes[a.currentIndex+1] = byte(v >> 8)
-	a.bytes[a.currentIndex] = byte(v)
-	a.currentIndex += 8
-}
-
-// WriteInt64 writes a int64 to the buffer in Big Endian order
+	data := a.reserve(8)
+	data[7] = byte(v >> 56)
+	data[6] = byte(v >> 48)
+	data[5] = byte(v >> 40)
+	data[4] = byte(v >> 32)
+	data[3] = byte(v >> 24)
+	data[2] = byte(v >> 16)
+	data[1] = byte(v >> 8)
+	data[0] = byte(v)
+}
+
+// WriteInt64 writes a int64 to the buffer in the byte order of the Writer
 func (a *FixedWriter) WriteInt64(v int64) {
-	a.bytes[a.currentIndex] = byte(v >> 56)
-	a.bytes[a.currentIndex+1] = byte(v >> 48)
-	a.bytes[a.currentIndex+2] = byte(v >> 40)
-	a.bytes[a.currentIndex+3] = byte(v >> 32)
-	a.bytes[a.currentIndex+4] = byte(v >> 24)
-	a.bytes[a.currentIndex+5] = byte(v >> 16)
-	a.bytes[a.currentIndex+6] = byte(v >> 8)
-	a.bytes[a.currentIndex+7] = byte(v)
-	a.currentIndex += 8
+	a.putUint64(a.reserve(8), uint64(v))
+}
+
+// WriteInt8 writes a signed byte onto the buffer
+func (a *FixedWriter) WriteInt8(v int8) {
+	a.WriteUInt8(uint8(v))
+}
+
+// WriteUInt16 writes an unsigned short in the byte order of the Writer
+func (a *FixedWriter) WriteUInt16(v uint16) {
+	a.WriteInt16(int16(v))
+}
+
+// WriteLEUInt16 writes an unsigned short in little endian order
+func (a *FixedWriter) WriteLEUInt16(v uint16) {
+	a.WriteLEInt16(int16(v))
+}
+
+// WriteUInt32 writes an unsigned int in the byte order of the Writer
+func (a *FixedWriter) WriteUInt32(v uint32) {
+	a.WriteInt32(int32(v))
+}
+
+// WriteLEUInt32 writes an unsigned int in little endian order
+func (a *FixedWriter) WriteLEUInt32(v uint32) {
+	a.WriteLEInt32(int32(v))
+}
+
+// WriteMEUInt32 writes an unsigned int in middle endian order
+func (a *FixedWriter) WriteMEUInt32(v uint32) {
+	a.WriteMEInt32(int32(v))
+}
+
+// WriteIMEUInt32 writes an unsigned int in inverse middle endian order
+func (a *FixedWriter) WriteIMEUInt32(v uint32) {
+	a.WriteIMEInt32(int32(v))
+}
+
+// WriteUInt64 writes an unsigned long in the byte order of the Writer
+func (a *FixedWriter) WriteUInt64(v uint64) {
+	a.WriteInt64(int64(v))
+}
+
+// WriteLEUInt64 writes an unsigned long in little endian order
+func (a *FixedWriter) WriteLEUInt64(v uint64) {
+	a.WriteLEInt64(int64(v))
+}
+
+// WriteFloat32 writes an IEEE-754 single precision float in the byte order of the Writer
+func (a *FixedWriter) WriteFloat32(v float32) {
+	a.WriteInt32(int32(math.Float32bits(v)))
+}
+
+// WriteLEFloat32 writes an IEEE-754 single precision float in little endian order
+func (a *FixedWriter) WriteLEFloat32(v float32) {
+	a.WriteLEInt32(int32(math.Float32bits(v)))
+}
+
+// WriteFloat64 writes an IEEE-754 double precision float in the byte order of the Writer
+func (a *FixedWriter) WriteFloat64(v float64) {
+	a.WriteInt64(int64(math.Float64bits(v)))
+}
+
+// WriteLEFloat64 writes an IEEE-754 double precision float in little endian order
+func (a *FixedWriter) WriteLEFloat64(v float64) {
+	a.WriteLEInt64(int64(math.Float64bits(v)))
 }
 
-// Write adds all the bytes to the payload
-func (a *FixedWriter) Write(v []byte) {
-	copy(a.bytes[a.currentIndex:], v)
-	a.currentIndex += len(v)
+// Write adds all the bytes to the payload. When the overflow policy drops v, no bytes are written and
+//	the recorded error or io.ErrShortWrite is returned.
+func (a *FixedWriter) Write(v []byte) (int, error) {
+	start := a.currentIndex
+	copy(a.reserve(len(v)), v)
+	if a.currentIndex-start != len(v) {
+		if a.err != nil {
+			return 0, a.err
+		}
+		return 0, io.ErrShortWrite
+	}
+	return len(v), nil
 }
 
 // WriteString writes a sequence of characters (string) followed by a delimiter byte
 func (a *FixedWriter) WriteString(value string, delim byte) {
-	copy(a.bytes[a.currentIndex:], value)
-	i := len(value)
-	a.bytes[a.currentIndex+i] = delim
-	a.currentIndex += 1 + i
+	data := a.reserve(len(value) + 1)
+	copy(data, value)
+	data[len(value)] = delim
 }
 
 // ExpandableWriter allows the array to grow past its capacity
@@ -173,14 +460,18 @@ var _ Writer = &ExpandableWriter{}
 
 type ExpandableWriter struct {
 	*bitWriter
+	// flush, when set, is called before size bytes are reserved.
+	flush func(size int)
 }
 
 // NewExpandableWriterWithCap makes a Writer interface with a initial soft capacity limit.
 //	NOTE: See benchmarks in terms of comparison between FixedWriter & ExpandableWriter to determine which you want.
 func NewExpandableWriterWithCap(size int) Writer {
 	return &ExpandableWriter{
-		&bitWriter{
-			bytes: make([]byte, 0, size),
+		bitWriter: &bitWriter{
+			bytes:     make([]byte, 0, size),
+			order:     binary.BigEndian,
+			bigEndian: true,
 		},
 	}
 }
@@ -188,78 +479,170 @@ func NewExpandableWriterWithCap(size int) Writer {
 // NewExpandableWriter makes a Writer interface that will expand as more is written to it.
 //	NOTE: See benchmarks in terms of comparison between FixedWriter & ExpandableWriter to determine which you want.
 func NewExpandableWriter() Writer {
+	return NewExpandableWriterWithOrder(binary.BigEndian)
+}
+
+// NewExpandableWriterWithOrder makes an expanding Writer where WriteInt16, WriteInt32, WriteInt64 and their
+//	unsigned and float variants follow the given byte order.
+func NewExpandableWriterWithOrder(order binary.ByteOrder) Writer {
 	return &ExpandableWriter{
-		&bitWriter{
-			bytes: make([]byte, 0),
+		bitWriter: &bitWriter{
+			bytes:     make([]byte, 0),
+			order:     order,
+			bigEndian: order == binary.BigEndian,
 		},
 	}
 }
 
+// reserve appends size bytes to the buffer and returns them to be written.
+func (a *ExpandableWriter) reserve(size int) []byte {
+	if a.flush != nil {
+		a.flush(size)
+	}
+	end := len(a.bytes) + size
+	if end <= cap(a.bytes) {
+		a.bytes = a.bytes[:end]
+	} else {
+		a.bytes = append(a.bytes, make([]byte, size)...)
+	}
+	a.currentIndex += size
+	return a.bytes[end-size : end]
+}
+
 // Writes a byte onto the buffer
 func (a *ExpandableWriter) WriteUInt8(v uint8) {
-	a.bytes = append(a.bytes, v)
-	a.currentIndex++
+	a.reserve(1)[0] = v
 }
 
-// WriteUInt16 writes two bytes to the buffer
+// WriteInt16 writes two bytes to the buffer in the byte order of the Writer
 func (a *ExpandableWriter) WriteInt16(v int16) {
-	a.bytes = append(a.bytes, byte(v>>8), byte(v))
-	a.currentIndex += 2
+	a.putUint16(a.reserve(2), uint16(v))
 }
 
 // WriteUInt16 writes two bytes in little endian to the buffer
 func (a *ExpandableWriter) WriteLEInt16(v int16) {
-	a.bytes = append(a.bytes, byte(v), byte(v>>8))
-	a.currentIndex += 2
+	binary.LittleEndian.PutUint16(a.reserve(2), uint16(v))
+}
+
+// WriteMedium writes the lower 24 bits of v in big endian order
+func (a *ExpandableWriter) WriteMedium(v int32) {
+	data := a.reserve(3)
+	data[0], data[1], data[2] = byte(v>>16), byte(v>>8), byte(v)
 }
 
-// WriteInt32 writes a integer to the byte buffer
+// WriteLEMedium writes the lower 24 bits of v in little endian order
+func (a *ExpandableWriter) WriteLEMedium(v int32) {
+	data := a.reserve(3)
+	data[0], data[1], data[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+// WriteInt32 writes a integer to the byte buffer in the byte order of the Writer
 func (a *ExpandableWriter) WriteInt32(v int32) {
-	a.bytes = append(a.bytes,
-		byte(v>>24), byte(v>>16), byte(v>>8), byte(v),
-	)
-	a.currentIndex += 4
+	a.putUint32(a.reserve(4), uint32(v))
 }
 
 // WriteLEInt32 writes a integer to the byte buffer in little Endian
 func (a *ExpandableWriter) WriteLEInt32(v int32) {
-	a.bytes = append(a.bytes,
-		byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
-	)
-	a.currentIndex += 4
+	binary.LittleEndian.PutUint32(a.reserve(4), uint32(v))
+}
+
+// WriteMEInt32 writes a int32 to the buffer in middle endian order, 0x0A0B0C0D is written as 0B 0A 0D 0C
+func (a *ExpandableWriter) WriteMEInt32(v int32) {
+	MiddleEndian.PutUint32(a.reserve(4), uint32(v))
+}
+
+// WriteIMEInt32 writes a int32 to the buffer in inverse middle endian order, 0x0A0B0C0D is written as 0C 0D 0A 0B
+func (a *ExpandableWriter) WriteIMEInt32(v int32) {
+	InverseMiddleEndian.PutUint32(a.reserve(4), uint32(v))
 }
 
-// WriteInt64 writes a int64 to the buffer in Big Endian order
+// WriteInt64 writes a int64 to the buffer in the byte order of the Writer
 func (a *ExpandableWriter) WriteInt64(v int64) {
-	a.bytes = append(a.bytes,
-		byte(v>>56), byte(v>>48), byte(v>>40),
-		byte(v>>32), byte(v>>24), byte(v>>16),
-		byte(v>>8), byte(v),
-	)
-	a.currentIndex += 8
+	a.putUint64(a.reserve(8), uint64(v))
 }
 
 // WriteLEInt64 writes a int64 to the buffer in Little Endian order
 func (a *ExpandableWriter) WriteLEInt64(v int64) {
-	a.bytes = append(a.bytes,
-		byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
-		byte(v>>32), byte(v>>40), byte(v>>48),
-		byte(v>>56),
-	)
-	a.currentIndex += 8
+	binary.LittleEndian.PutUint64(a.reserve(8), uint64(v))
+}
+
+// WriteInt8 writes a signed byte onto the buffer
+func (a *ExpandableWriter) WriteInt8(v int8) {
+	a.WriteUInt8(uint8(v))
+}
+
+// WriteUInt16 writes an unsigned short in the byte order of the Writer
+func (a *ExpandableWriter) WriteUInt16(v uint16) {
+	a.WriteInt16(int16(v))
+}
+
+// WriteLEUInt16 writes an unsigned short in little endian order
+func (a *ExpandableWriter) WriteLEUInt16(v uint16) {
+	a.WriteLEInt16(int16(v))
+}
+
+// WriteUInt32 writes an unsigned int in the byte order of the Writer
+func (a *ExpandableWriter) WriteUInt32(v uint32) {
+	a.WriteInt32(int32(v))
+}
+
+// WriteLEUInt32 writes an unsigned int in little endian order
+func (a *ExpandableWriter) WriteLEUInt32(v uint32) {
+	a.WriteLEInt32(int32(v))
+}
+
+// WriteMEUInt32 writes an unsigned int in middle endian order
+func (a *ExpandableWriter) WriteMEUInt32(v uint32) {
+	a.WriteMEInt32(int32(v))
+}
+
+// WriteIMEUInt32 writes an unsigned int in inverse middle endian order
+func (a *ExpandableWriter) WriteIMEUInt32(v uint32) {
+	a.WriteIMEInt32(int32(v))
+}
+
+// WriteUInt64 writes an unsigned long in the byte order of the Writer
+func (a *ExpandableWriter) WriteUInt64(v uint64) {
+	a.WriteInt64(int64(v))
+}
+
+// WriteLEUInt64 writes an unsigned long in little endian order
+func (a *ExpandableWriter) WriteLEUInt64(v uint64) {
+	a.WriteLEInt64(int64(v))
+}
+
+// WriteFloat32 writes an IEEE-754 single precision float in the byte order of the Writer
+func (a *ExpandableWriter) WriteFloat32(v float32) {
+	a.WriteInt32(int32(math.Float32bits(v)))
+}
+
+// WriteLEFloat32 writes an IEEE-754 single precision float in little endian order
+func (a *ExpandableWriter) WriteLEFloat32(v float32) {
+	a.WriteLEInt32(int32(math.Float32bits(v)))
+}
+
+// WriteFloat64 writes an IEEE-754 double precision float in the byte order of the Writer
+func (a *ExpandableWriter) WriteFloat64(v float64) {
+	a.WriteInt64(int64(math.Float64bits(v)))
+}
+
+// WriteLEFloat64 writes an IEEE-754 double precision float in little endian order
+func (a *ExpandableWriter) WriteLEFloat64(v float64) {
+	a.WriteLEInt64(int64(math.Float64bits(v)))
 }
 
-// Write adds all the bytes to the payload
-func (a *ExpandableWriter) Write(v []byte) {
-	a.bytes = append(a.bytes, v...)
-	a.currentIndex += len(v)
+// Write adds all the bytes to the payload, it always writes all of v.
+func (a *ExpandableWriter) Write(v []byte) (int, error) {
+	copy(a.reserve(len(v)), v)
+	return len(v), nil
 }
 
 // AppendString writes a sequence of characters (string) followed by a delimiter byte
 //	NOTE: This will go beyond the size of the buffered Array. If not desired, use WriteString instead.
 func (a *ExpandableWriter) WriteString(value string, delim byte) {
-	a.bytes = append(a.bytes, []byte(value)...)
-	a.bytes = append(a.bytes, delim)
+	data := a.reserve(len(value) + 1)
+	copy(data, value)
+	data[len(value)] = delim
 }
 
 func init() {
@@ -1,9 +1,11 @@
 package bytepal
 
 import (
+	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"io"
 	"testing"
 )
 
@@ -119,6 +121,78 @@ func BenchmarkExpandableWriter_Write2(b *testing.B) {
 	}
 }
 
+func TestFixedWriter_OverflowPanic(t *testing.T) {
+	out := NewFixedWriter(3)
+	assert.Panics(t, func() { out.WriteInt32(1) })
+	assert.Panics(t, func() { out.Write([]byte{1, 2, 3, 4}) })
+}
+func TestFixedWriter_OverflowError(t *testing.T) {
+	out := NewFixedWriterWithPolicy(3, OverflowError)
+	out.WriteInt16(0x102)
+	assert.NoError(t, out.Err())
+	out.WriteInt16(0x304)
+
+	var writeErr *WriteError
+	require.True(t, errors.As(out.Err(), &writeErr))
+	assert.Equal(t, 2, writeErr.Offset)
+	assert.Equal(t, 2, writeErr.Size)
+	assert.Equal(t, 1, writeErr.Available)
+
+	// The error is sticky, writes that would fit are dropped too.
+	out.WriteUInt8(5)
+	assert.Equal(t, []byte{1, 2, 0}, out.Payload())
+	assert.Equal(t, writeErr, out.Err())
+}
+func TestFixedWriter_OverflowDrop(t *testing.T) {
+	out := NewFixedWriterWithPolicy(3, OverflowDrop)
+	out.WriteInt16(0x102)
+	out.WriteString(TestString, Delim)
+	out.WriteUInt8(3)
+	assert.NoError(t, out.Err())
+	assert.Equal(t, []byte{1, 2, 3}, out.Payload())
+}
+func TestFixedWriter_WriteShort(t *testing.T) {
+	out := NewFixedWriterWithPolicy(3, OverflowDrop)
+	n, err := out.Write([]byte{1, 2})
+	assert.Equal(t, 2, n)
+	assert.NoError(t, err)
+	n, err = out.Write([]byte{3, 4})
+	assert.Equal(t, 0, n)
+	assert.Equal(t, io.ErrShortWrite, err)
+
+	out = NewFixedWriterWithPolicy(1, OverflowError)
+	_, err = out.Write([]byte{1, 2})
+	assert.IsType(t, &WriteError{}, err)
+}
+func TestFixedWriter_OverflowBits(t *testing.T) {
+	out := NewFixedWriterWithPolicy(1, OverflowError)
+	bits := out.BitAccess()
+	bits(4, 0xA)
+	bits(16, 0xFFFF)
+	assert.Equal(t, 1, out.Size())
+	assert.Equal(t, &WriteError{Offset: 0, Size: 3, Available: 1}, out.Err())
+	bits(4, 0xB)
+	assert.Equal(t, []byte{0xA0}, out.Payload())
+
+	out = NewFixedWriterWithPolicy(1, OverflowDrop)
+	bits = out.BitAccess()
+	bits(16, 0xFFFF)
+	bits(8, 0xAB)
+	assert.NoError(t, out.Err())
+	assert.Equal(t, []byte{0xAB}, out.Payload())
+
+	out = NewFixedWriterWithPolicy(1, OverflowGrow)
+	out.BitAccess()(16, 0x1234)
+	assert.Equal(t, []byte{0x12, 0x34}, out.Payload())
+}
+func TestFixedWriter_OverflowGrow(t *testing.T) {
+	out := NewFixedWriterWithPolicy(1, OverflowGrow)
+	out.WriteInt32(0x1020304)
+	out.WriteLEInt16(0x506)
+	assert.NoError(t, out.Err())
+	assert.Equal(t, []byte{1, 2, 3, 4, 6, 5}, out.Payload())
+}
+
 func TestFixedWriter_WriteUInt8(t *testing.T) {
 	out := NewFixedWriter(1)
 	out.WriteUInt8(1)
@@ -457,3 +531,78 @@ func BenchmarkExpandableWriter_WriteString2(b *testing.B) {
 		out.WriteString(TestString, Delim)
 	}
 }
+
+func testRoundTrip(t *testing.T, out Writer) {
+	out.WriteInt8(-2)
+	out.WriteUInt8(254)
+	out.WriteInt16(-1234)
+	out.WriteUInt16(65000)
+	out.WriteLEInt16(-1234)
+	out.WriteLEUInt16(65000)
+	out.WriteMedium(-70000)
+	out.WriteMedium(0x7FFFFF)
+	out.WriteLEMedium(-70000)
+	out.WriteLEMedium(0xABCDEF)
+	out.WriteInt32(-123456789)
+	out.WriteUInt32(4000000000)
+	out.WriteLEInt32(-123456789)
+	out.WriteLEUInt32(4000000000)
+	out.WriteMEInt32(-123456789)
+	out.WriteMEUInt32(4000000000)
+	out.WriteIMEInt32(-123456789)
+	out.WriteIMEUInt32(4000000000)
+	out.WriteInt64(-1234567890123)
+	out.WriteUInt64(18000000000000000000)
+	out.WriteLEInt64(-1234567890123)
+	out.WriteLEUInt64(18000000000000000000)
+	out.WriteFloat32(1.5)
+	out.WriteLEFloat32(-0.25)
+	out.WriteFloat64(3.141592653589793)
+	out.WriteLEFloat64(-2.5e100)
+	require.NoError(t, out.Err())
+
+	in := NewCheckedReader(out.Payload())
+	assert.Equal(t, int8(-2), in.ReadInt8())
+	assert.Equal(t, uint8(254), in.ReadUInt8())
+	assert.Equal(t, int16(-1234), in.ReadInt16())
+	assert.Equal(t, uint16(65000), in.ReadUInt16())
+	assert.Equal(t, int16(-1234), in.ReadLEInt16())
+	assert.Equal(t, uint16(65000), in.ReadLEUInt16())
+	assert.Equal(t, int32(-70000), in.ReadMedium())
+	assert.Equal(t, uint32(0x7FFFFF), in.ReadUMedium())
+	assert.Equal(t, int32(-70000), in.ReadLEMedium())
+	assert.Equal(t, uint32(0xABCDEF), in.ReadLEUMedium())
+	assert.Equal(t, int32(-123456789), in.ReadInt32())
+	assert.Equal(t, uint32(4000000000), in.ReadUInt32())
+	assert.Equal(t, int32(-123456789), in.ReadLEInt32())
+	assert.Equal(t, uint32(4000000000), in.ReadLEUInt32())
+	assert.Equal(t, int32(-123456789), in.ReadMEInt32())
+	assert.Equal(t, uint32(4000000000), in.ReadMEUInt32())
+	assert.Equal(t, int32(-123456789), in.ReadIMEInt32())
+	assert.Equal(t, uint32(4000000000), in.ReadIMEUInt32())
+	assert.Equal(t, int64(-1234567890123), in.ReadInt64())
+	assert.Equal(t, uint64(18000000000000000000), in.ReadUInt64())
+	assert.Equal(t, int64(-1234567890123), in.ReadLEInt64())
+	assert.Equal(t, uint64(18000000000000000000), in.ReadLEUInt64())
+	assert.Equal(t, float32(1.5), in.ReadFloat32())
+	assert.Equal(t, float32(-0.25), in.ReadLEFloat32())
+	assert.Equal(t, 3.141592653589793, in.ReadFloat64())
+	assert.Equal(t, -2.5e100, in.ReadLEFloat64())
+	assert.NoError(t, in.Err())
+	assert.Equal(t, 0, in.Remaining())
+}
+
+func TestFixedWriter_RoundTrip(t *testing.T) {
+	testRoundTrip(t, NewFixedWriter(110))
+}
+func TestExpandableWriter_RoundTrip(t *testing.T) {
+	testRoundTrip(t, NewExpandableWriter())
+}
+
+func TestWriter_MiddleEndian(t *testing.T) {
+	for _, out := range []Writer{NewFixedWriter(8), NewExpandableWriter()} {
+		out.WriteMEInt32(0x0A0B0C0D)
+		out.WriteIMEInt32(0x0A0B0C0D)
+		assert.Equal(t, []byte{0x0B, 0x0A, 0x0D, 0x0C, 0x0C, 0x0D, 0x0A, 0x0B}, out.Payload())
+	}
+}
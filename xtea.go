@@ -0,0 +1,80 @@
+package bytepal
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const xteaDelta uint32 = 0x9E3779B9
+
+// XTEA is a key for the XTEA block cipher, blocks are 8 bytes holding two big endian ints.
+type XTEA struct {
+	Keys [4]uint32
+	// Rounds is the number of cycles, each made of two Feistel rounds.
+	Rounds int
+}
+
+// NewXTEA creates an XTEA key using the standard 32 cycles.
+func NewXTEA(keys [4]uint32) *XTEA {
+	return &XTEA{
+		Keys:   keys,
+		Rounds: 32,
+	}
+}
+
+// Encrypt encrypts every whole block of data between start and end in place, a trailing partial block is left as is.
+//	A region of a Writer is encrypted through its Payload, x.Encrypt(w.Payload(), start, w.Size()).
+//	A range outside of data returns an error wrapping ErrRange.
+func (x *XTEA) Encrypt(data []byte, start, end int) error {
+	if err := checkXTEARange(data, start, end); err != nil {
+		return err
+	}
+	for i := start; i+8 <= end; i += 8 {
+		v0, v1 := binary.BigEndian.Uint32(data[i:]), binary.BigEndian.Uint32(data[i+4:])
+		sum := uint32(0)
+		for round := 0; round < x.Rounds; round++ {
+			v0 += ((v1<<4 ^ v1>>5) + v1) ^ (sum + x.Keys[sum&3])
+			sum += xteaDelta
+			v1 += ((v0<<4 ^ v0>>5) + v0) ^ (sum + x.Keys[sum>>11&3])
+		}
+		binary.BigEndian.PutUint32(data[i:], v0)
+		binary.BigEndian.PutUint32(data[i+4:], v1)
+	}
+	return nil
+}
+
+// Decrypt decrypts every whole block of data between start and end in place, a trailing partial block is left as is.
+//	A range outside of data returns an error wrapping ErrRange.
+func (x *XTEA) Decrypt(data []byte, start, end int) error {
+	if err := checkXTEARange(data, start, end); err != nil {
+		return err
+	}
+	for i := start; i+8 <= end; i += 8 {
+		v0, v1 := binary.BigEndian.Uint32(data[i:]), binary.BigEndian.Uint32(data[i+4:])
+		sum := xteaDelta * uint32(x.Rounds)
+		for round := 0; round < x.Rounds; round++ {
+			v1 -= ((v0<<4 ^ v0>>5) + v0) ^ (sum + x.Keys[sum>>11&3])
+			sum -= xteaDelta
+			v0 -= ((v1<<4 ^ v1>>5) + v1) ^ (sum + x.Keys[sum&3])
+		}
+		binary.BigEndian.PutUint32(data[i:], v0)
+		binary.BigEndian.PutUint32(data[i+4:], v1)
+	}
+	return nil
+}
+
+// checkXTEARange fails a range that does not lie within data.
+func checkXTEARange(data []byte, start, end int) error {
+	if start < 0 || start > end || end > len(data) {
+		return fmt.Errorf("%w: xtea range %d to %d of %d bytes", ErrRange, start, end, len(data))
+	}
+	return nil
+}
+
+// DecryptXTEA decrypts the payload between start and end in place, see XTEA.Decrypt. A range past the
+//	limit of the Reader fails it with an error wrapping ErrRange.
+func (b *Reader) DecryptXTEA(x *XTEA, start, end int) {
+	if err := x.Decrypt(b.bytes[:b.limit], start, end); err != nil {
+		b.fail(err)
+	}
+}
@@ -0,0 +1,74 @@
+package bytepal
+
+import (
+	"encoding/hex"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestXTEA_KnownAnswer(t *testing.T) {
+	tests := []struct {
+		keys                  [4]uint32
+		plaintext, ciphertext string
+	}{
+		{[4]uint32{0x00010203, 0x04050607, 0x08090A0B, 0x0C0D0E0F}, "4142434445464748", "497df3d072612cb5"},
+		{[4]uint32{0x00010203, 0x04050607, 0x08090A0B, 0x0C0D0E0F}, "4141414141414141", "e78f2d13744341d8"},
+		{[4]uint32{}, "4142434445464748", "a0390589f8b8efa5"},
+	}
+	for _, test := range tests {
+		x := NewXTEA(test.keys)
+		data, _ := hex.DecodeString(test.plaintext)
+		require.NoError(t, x.Encrypt(data, 0, len(data)))
+		assert.Equal(t, test.ciphertext, hex.EncodeToString(data))
+		require.NoError(t, x.Decrypt(data, 0, len(data)))
+		assert.Equal(t, test.plaintext, hex.EncodeToString(data))
+	}
+}
+
+func TestXTEA_EncryptPayload(t *testing.T) {
+	x := NewXTEA([4]uint32{1, 2, 3, 4})
+	x.Rounds = 16
+	for _, out := range []Writer{NewFixedWriter(23), NewExpandableWriter()} {
+		out.WriteUInt8(0xAA)
+		for i := 0; i < 5; i++ {
+			out.WriteInt32(int32(i))
+		}
+		out.WriteInt16(0x0102)
+		require.NoError(t, x.Encrypt(out.Payload(), 1, out.Size()))
+
+		// The leading byte and the trailing partial block are untouched
+		assert.Equal(t, byte(0xAA), out.Payload()[0])
+		assert.Equal(t, []byte{0, 0, 0, 4, 1, 2}, out.Payload()[17:])
+
+		in := NewCheckedReader(out.Payload())
+		in.DecryptXTEA(x, 1, len(in.Payload()))
+		assert.Equal(t, uint8(0xAA), in.ReadUInt8())
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, int32(i), in.ReadInt32())
+		}
+		assert.Equal(t, int16(0x0102), in.ReadInt16())
+		assert.NoError(t, in.Err())
+	}
+}
+
+func TestXTEA_OutOfRange(t *testing.T) {
+	x := NewXTEA([4]uint32{})
+	in := NewCheckedReader(make([]byte, 8))
+	in.DecryptXTEA(x, 0, 16)
+	assert.True(t, errors.Is(in.Err(), ErrRange), "%v", in.Err())
+
+	// The limit of the Reader bounds the range.
+	in = NewCheckedReader(make([]byte, 16))
+	in.PushLimit(8)
+	in.DecryptXTEA(x, 0, 16)
+	assert.True(t, errors.Is(in.Err(), ErrRange), "%v", in.Err())
+
+	data := make([]byte, 8)
+	for _, r := range [][2]int{{-1, 8}, {0, 9}, {8, 0}} {
+		assert.True(t, errors.Is(x.Encrypt(data, r[0], r[1]), ErrRange), "%v", r)
+		assert.True(t, errors.Is(x.Decrypt(data, r[0], r[1]), ErrRange), "%v", r)
+	}
+	assert.Equal(t, make([]byte, 8), data)
+}